@@ -0,0 +1,23 @@
+package main
+
+import (
+	"log"
+
+	"github.com/example/duolingocards-backend/internal/config"
+	"github.com/example/duolingocards-backend/internal/services"
+)
+
+// Computes BlurHash, size and dominant color for card images stored before
+// placeholders were generated
+func main() {
+	cfg := config.Load()
+
+	generator := services.NewGenerator(cfg)
+
+	updated, err := generator.BackfillPlaceholders()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("Updated placeholders for %d cards", updated)
+}
@@ -8,13 +8,17 @@ import (
 	"github.com/example/duolingocards-backend/internal/api"
 	"github.com/example/duolingocards-backend/internal/config"
 	"github.com/example/duolingocards-backend/internal/services"
+	"github.com/example/duolingocards-backend/internal/services/webhooks"
 )
 
 func main() {
 	cfg := config.Load()
 
+	dispatcher := webhooks.NewDispatcher(cfg.StatePath("webhooks"), cfg.WebhookMaxAttempts)
+
 	generator := services.NewGenerator(cfg)
-	handlers := api.NewHandlers(generator, cfg)
+	generator.SetWebhooks(dispatcher)
+	handlers := api.NewHandlers(generator, dispatcher, cfg)
 
 	mux := http.NewServeMux()
 	api.SetupRoutes(mux, handlers, cfg)
@@ -26,6 +30,7 @@ func main() {
 	log.Printf("Starting server on %s", addr)
 	log.Printf("Storage path: %s", cfg.StoragePath)
 	log.Printf("Storage base URL: %s", cfg.StorageBaseURL)
+	log.Printf("Data path: %s", cfg.DataPath)
 
 	if err := http.ListenAndServe(addr, handler); err != nil {
 		log.Fatal(err)
@@ -35,7 +40,7 @@ func main() {
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 		if r.Method == "OPTIONS" {
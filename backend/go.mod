@@ -1,3 +1,9 @@
 module github.com/example/duolingocards-backend
 
 go 1.24.5
+
+require github.com/hajimehoshi/go-mp3 v0.3.4
+
+require golang.org/x/image v0.24.0
+
+require golang.org/x/text v0.22.0
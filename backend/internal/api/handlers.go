@@ -1,31 +1,85 @@
 package api
 
 import (
+	"cmp"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/example/duolingocards-backend/internal/config"
 	"github.com/example/duolingocards-backend/internal/models"
 	"github.com/example/duolingocards-backend/internal/services"
+	"github.com/example/duolingocards-backend/internal/services/events"
 	"github.com/example/duolingocards-backend/internal/services/iap"
+	"github.com/example/duolingocards-backend/internal/services/locale"
+	"github.com/example/duolingocards-backend/internal/services/recorder"
+	"github.com/example/duolingocards-backend/internal/services/usage"
+	"github.com/example/duolingocards-backend/internal/services/webhooks"
 )
 
 type Handlers struct {
 	generator    *services.Generator
 	iapValidator *iap.Validator
+	webhooks     *webhooks.Dispatcher
 	cfg          *config.Config
 }
 
-func NewHandlers(generator *services.Generator, cfg *config.Config) *Handlers {
+func NewHandlers(generator *services.Generator, dispatcher *webhooks.Dispatcher, cfg *config.Config) *Handlers {
+	validator := iap.NewValidator(cfg.AppleSharedSecret, cfg.GooglePackageName, cfg.IAPSandboxMode)
+	validator.SetTransport(services.ProviderTransport(cfg, "apple", cfg.AppleHTTPMode, http.DefaultTransport,
+		recorder.Redaction{JSONFields: []string{"password"}}))
+
 	return &Handlers{
 		generator:    generator,
-		iapValidator: iap.NewValidator(cfg.AppleSharedSecret, cfg.GooglePackageName, cfg.IAPSandboxMode),
+		iapValidator: validator,
+		webhooks:     dispatcher,
 		cfg:          cfg,
 	}
 }
 
+// GetCatalog lists decks for the store
+// Query: frontLanguage, backLanguage, price (free or paid), level, tags
+// (comma separated, all required), q (free text), sort (popularity, newest
+// or name), limit and cursor (nextCursor of the previous page)
 func (h *Handlers) GetCatalog(w http.ResponseWriter, r *http.Request) {
-	catalog := h.generator.GetCatalog()
+	query := r.URL.Query()
+	q := services.CatalogQuery{
+		FrontLanguage: query.Get("frontLanguage"),
+		BackLanguage:  query.Get("backLanguage"),
+		Price:         query.Get("price"),
+		Level:         query.Get("level"),
+		Search:        query.Get("q"),
+		Sort:          query.Get("sort"),
+		Cursor:        query.Get("cursor"),
+		Languages:     requestLanguages(r),
+	}
+	for _, tag := range strings.Split(query.Get("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			q.Tags = append(q.Tags, tag)
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "limit must be a number")
+			return
+		}
+		q.Limit = n
+	}
+
+	catalog, err := h.generator.GetCatalog(q)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	setLanguageHeaders(w, nil, "")
 	writeJSON(w, http.StatusOK, catalog)
 }
 
@@ -36,11 +90,13 @@ func (h *Handlers) GetDeckPreview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	preview, err := h.generator.GetDeckPreview(deckID)
+	languages := requestLanguages(r)
+	preview, err := h.generator.GetDeckPreview(deckID, languages)
 	if err != nil {
 		writeError(w, http.StatusNotFound, err.Error())
 		return
 	}
+	setLanguageHeaders(w, languages, preview.BackLanguage)
 
 	writeJSON(w, http.StatusOK, preview)
 }
@@ -52,11 +108,13 @@ func (h *Handlers) GetDeck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	deck, err := h.generator.GetDeck(deckID)
+	languages := requestLanguages(r)
+	deck, err := h.generator.GetDeck(deckID, languages)
 	if err != nil {
 		writeError(w, http.StatusNotFound, err.Error())
 		return
 	}
+	setLanguageHeaders(w, languages, deck.BackLanguage)
 
 	writeJSON(w, http.StatusOK, deck)
 }
@@ -76,6 +134,10 @@ func (h *Handlers) GenerateDeck(w http.ResponseWriter, r *http.Request) {
 	req.DeckID = deckID
 
 	status, err := h.generator.StartGeneration(req)
+	if errors.Is(err, services.ErrBudgetExceeded) {
+		writeError(w, http.StatusPaymentRequired, err.Error())
+		return
+	}
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -84,6 +146,167 @@ func (h *Handlers) GenerateDeck(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusAccepted, status)
 }
 
+// CreateDeck validates and stores a deck, such as a reviewed draft
+func (h *Handlers) CreateDeck(w http.ResponseWriter, r *http.Request) {
+	var deck models.Deck
+	if err := json.NewDecoder(r.Body).Decode(&deck); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	err := h.generator.CreateDeck(&deck)
+	if errors.Is(err, services.ErrDeckExists) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, deck)
+}
+
+// DraftDeck drafts a deck with the language model for editor review, nothing is stored
+func (h *Handlers) DraftDeck(w http.ResponseWriter, r *http.Request) {
+	var req models.DeckDraftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	draft, err := h.generator.DraftDeck(r.Context(), req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, draft)
+}
+
+// TranslateDeck machine-translates missing card translations into a language
+func (h *Handlers) TranslateDeck(w http.ResponseWriter, r *http.Request) {
+	var req models.DeckTranslateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	deck, err := h.generator.TranslateDeck(r.Context(), r.PathValue("id"), req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deck)
+}
+
+// CloneDeck copies a deck into another back language with machine-translated back texts
+func (h *Handlers) CloneDeck(w http.ResponseWriter, r *http.Request) {
+	var req models.DeckCloneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	deck, err := h.generator.CloneDeck(r.Context(), r.PathValue("id"), req)
+	if errors.Is(err, services.ErrDeckExists) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, deck)
+}
+
+// ReviewCard clears the needs-review mark of a cloned card
+func (h *Handlers) ReviewCard(w http.ResponseWriter, r *http.Request) {
+	var review models.CardReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		// Empty body is OK, the translation is accepted as is
+		review = models.CardReview{}
+	}
+
+	card, err := h.generator.ReviewCard(r.PathValue("id"), r.PathValue("cardId"), review)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, card)
+}
+
+// EstimateGeneration takes the same body as GenerateDeck and returns its cost without generating
+func (h *Handlers) EstimateGeneration(w http.ResponseWriter, r *http.Request) {
+	var req models.GenerateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		req = models.GenerateRequest{}
+	}
+	req.DeckID = r.PathValue("id")
+
+	estimate, err := h.generator.EstimateGeneration(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, estimate)
+}
+
+// GetUsage reports recorded provider usage
+// Query: from and to (YYYY-MM-DD, inclusive), deckId, provider
+func (h *Handlers) GetUsage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := usage.Filter{
+		From:     query.Get("from"),
+		To:       query.Get("to"),
+		DeckID:   query.Get("deckId"),
+		Provider: query.Get("provider"),
+	}
+
+	for _, date := range []string{filter.From, filter.To} {
+		if date != "" && !usage.ValidDate(date) {
+			writeError(w, http.StatusBadRequest, "dates must be YYYY-MM-DD")
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, h.generator.UsageReport(filter))
+}
+
+func (h *Handlers) CancelGeneration(w http.ResponseWriter, r *http.Request) {
+	status, err := h.generator.CancelGeneration(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (h *Handlers) PauseGeneration(w http.ResponseWriter, r *http.Request) {
+	status, err := h.generator.PauseGeneration(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (h *Handlers) ResumeGeneration(w http.ResponseWriter, r *http.Request) {
+	status, err := h.generator.ResumeGeneration(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
 func (h *Handlers) GetGenerateStatus(w http.ResponseWriter, r *http.Request) {
 	deckID := r.PathValue("id")
 	if deckID == "" {
@@ -100,6 +323,64 @@ func (h *Handlers) GetGenerateStatus(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, status)
 }
 
+// sseHeartbeat keeps idle event streams open through proxies
+const sseHeartbeat = 15 * time.Second
+
+// StreamEvents streams generation progress for a deck as Server-Sent Events
+func (h *Handlers) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	deckID := r.PathValue("id")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	snapshot, stream, unsubscribe, err := h.generator.Subscribe(deckID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if snapshot != nil {
+		writeEvent(w, *snapshot)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case event, ok := <-stream:
+			if !ok {
+				return
+			}
+			writeEvent(w, event)
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w io.Writer, event events.Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+}
+
 func (h *Handlers) DownloadDeck(w http.ResponseWriter, r *http.Request) {
 	deckID := r.PathValue("id")
 	if deckID == "" {
@@ -126,7 +407,7 @@ func (h *Handlers) DownloadDeck(w http.ResponseWriter, r *http.Request) {
 		verifyReq := iap.VerifyRequest{
 			Platform:    downloadReq.Platform,
 			ReceiptData: downloadReq.ReceiptData,
-			ProductID:   "com.example.duolingocards.deck." + deckID,
+			ProductID:   iap.DeckProductID(deckID),
 			DeckID:      deckID,
 		}
 
@@ -143,11 +424,14 @@ func (h *Handlers) DownloadDeck(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Return the deck
-	deck, err := h.generator.GetDeck(deckID)
+	languages := requestLanguages(r)
+	deck, err := h.generator.GetDeck(deckID, languages)
 	if err != nil {
 		writeError(w, http.StatusNotFound, err.Error())
 		return
 	}
+	setLanguageHeaders(w, languages, deck.BackLanguage)
+	h.generator.RecordDownload(deckID)
 
 	writeJSON(w, http.StatusOK, deck)
 }
@@ -170,9 +454,364 @@ func (h *Handlers) VerifyReceipt(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Receipts are verified again on restore and reinstall, only the first time is a purchase
+	if result.Valid {
+		transaction := cmp.Or(result.TransactionID, req.ReceiptData)
+		h.webhooks.PublishOnce(webhooks.PurchaseVerified, strings.ToLower(req.Platform)+":"+result.ProductID+":"+transaction, map[string]string{
+			"platform":  req.Platform,
+			"deckId":    result.DeckID,
+			"productId": result.ProductID,
+		})
+	}
+
 	writeJSON(w, http.StatusOK, result)
 }
 
+// ValidateReadings lists cards whose reading is missing or differs from the computed one
+func (h *Handlers) ValidateReadings(w http.ResponseWriter, r *http.Request) {
+	issues, err := h.generator.ValidateReadings(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"issues": issues})
+}
+
+func (h *Handlers) PreviewImagePrompt(w http.ResponseWriter, r *http.Request) {
+	deckID := r.PathValue("id")
+	cardID := r.PathValue("cardId")
+	if deckID == "" || cardID == "" {
+		writeError(w, http.StatusBadRequest, "deck id and card id required")
+		return
+	}
+
+	preview, err := h.generator.PreviewImagePrompt(deckID, cardID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, preview)
+}
+
+func (h *Handlers) ListCandidates(w http.ResponseWriter, r *http.Request) {
+	reviews, err := h.generator.ListCandidates(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"cards": reviews})
+}
+
+func (h *Handlers) SelectCandidate(w http.ResponseWriter, r *http.Request) {
+	card, err := h.generator.SelectCandidate(r.PathValue("id"), r.PathValue("cardId"), r.PathValue("candidateId"))
+	if errors.Is(err, services.ErrGenerationRunning) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, card)
+}
+
+func (h *Handlers) RejectCandidates(w http.ResponseWriter, r *http.Request) {
+	status, err := h.generator.RejectCandidates(r.PathValue("id"), r.PathValue("cardId"))
+	if errors.Is(err, services.ErrBudgetExceeded) {
+		writeError(w, http.StatusPaymentRequired, err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrGenerationRunning) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, status)
+}
+
+func (h *Handlers) GenerateExamples(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Count int `json:"count"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		// Empty body is OK, the default count is used
+		req.Count = 0
+	}
+
+	card, err := h.generator.GenerateExamples(r.Context(), r.PathValue("id"), r.PathValue("cardId"), req.Count)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, card)
+}
+
+func (h *Handlers) UpdateExample(w http.ResponseWriter, r *http.Request) {
+	var update models.ExampleUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	card, err := h.generator.UpdateExample(r.PathValue("id"), r.PathValue("cardId"), r.PathValue("exampleId"), update)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, card)
+}
+
+func (h *Handlers) DeleteExample(w http.ResponseWriter, r *http.Request) {
+	card, err := h.generator.DeleteExample(r.PathValue("id"), r.PathValue("cardId"), r.PathValue("exampleId"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, card)
+}
+
+// maxImageUpload limits replacement image uploads
+const maxImageUpload = 10 << 20
+
+func (h *Handlers) ReplaceImage(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImageUpload))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, "image too large")
+		return
+	}
+	if len(data) == 0 {
+		writeError(w, http.StatusBadRequest, "image data required")
+		return
+	}
+
+	card, err := h.generator.ReplaceImage(r.PathValue("id"), r.PathValue("cardId"), data)
+	if errors.Is(err, services.ErrGenerationRunning) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, card)
+}
+
+func (h *Handlers) ListStyles(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"styles": h.generator.ListStyles()})
+}
+
+func (h *Handlers) GetStyle(w http.ResponseWriter, r *http.Request) {
+	style, err := h.generator.GetStyle(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, style)
+}
+
+func (h *Handlers) CreateStyle(w http.ResponseWriter, r *http.Request) {
+	var style models.StylePreset
+	if err := json.NewDecoder(r.Body).Decode(&style); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	err := h.generator.CreateStyle(&style)
+	if errors.Is(err, services.ErrStyleExists) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, style)
+}
+
+func (h *Handlers) UpdateStyle(w http.ResponseWriter, r *http.Request) {
+	styleID := r.PathValue("id")
+
+	var style models.StylePreset
+	if err := json.NewDecoder(r.Body).Decode(&style); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	style.ID = styleID
+
+	if err := h.generator.SaveStyle(&style); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, style)
+}
+
+func (h *Handlers) DeleteStyle(w http.ResponseWriter, r *http.Request) {
+	err := h.generator.DeleteStyle(r.PathValue("id"))
+	if errors.Is(err, services.ErrStyleNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handlers) ListVoices(w http.ResponseWriter, r *http.Request) {
+	voices, err := h.generator.ListVoices()
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"voices": voices})
+}
+
+// SearchCards finds cards across decks
+// Query: q (words, kana or kanji) and limit (default 50); POST takes the same
+// fields as JSON plus platform and receiptData so bought paid decks show in full;
+// Android purchase tokens also need the productId they were bought for
+func (h *Handlers) SearchCards(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Query       string `json:"q"`
+		Limit       int    `json:"limit"`
+		Platform    string `json:"platform"`
+		ReceiptData string `json:"receiptData"`
+		ProductID   string `json:"productId"`
+	}
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	} else {
+		query := r.URL.Query()
+		req.Query = query.Get("q")
+		if value := query.Get("limit"); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "limit must be a number")
+				return
+			}
+			req.Limit = n
+		}
+	}
+
+	access := services.SearchAccess{Editor: h.isEditor(r)}
+	if req.ReceiptData != "" {
+		// The receipt is verified at most once per search, and only if paid decks match
+		var purchases map[string]bool
+		access.Owned = func(deckIDs []string) map[string]bool {
+			if purchases == nil {
+				var err error
+				if purchases, err = h.iapValidator.Purchases(req.Platform, req.ReceiptData, req.ProductID); err != nil {
+					purchases = map[string]bool{}
+				}
+			}
+			return iap.OwnedDecks(purchases, deckIDs)
+		}
+	}
+
+	results, err := h.generator.Search(req.Query, requestLanguages(r), req.Limit, access)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	setLanguageHeaders(w, nil, "")
+	writeJSON(w, http.StatusOK, results)
+}
+
+// isEditor reports whether the request carries the configured editor token
+func (h *Handlers) isEditor(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && h.cfg.EditorToken != "" &&
+		subtle.ConstantTimeCompare([]byte(token), []byte(h.cfg.EditorToken)) == 1
+}
+
+// ListLanguages returns the language registry for deck editors
+func (h *Handlers) ListLanguages(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, locale.Languages())
+}
+
+func (h *Handlers) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"webhooks": h.webhooks.Subscriptions()})
+}
+
+func (h *Handlers) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var sub webhooks.Subscription
+	if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	created, err := h.webhooks.AddSubscription(sub)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, created)
+}
+
+func (h *Handlers) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if err := h.webhooks.DeleteSubscription(r.PathValue("id")); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handlers) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"deliveries": h.webhooks.Deliveries()})
+}
+
+func (h *Handlers) RedeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	delivery, err := h.webhooks.Redeliver(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, delivery)
+}
+
+// requestLanguages returns the languages a client prefers, the lang query
+// parameter (e.g. "en" or "de,en") takes precedence over Accept-Language
+func requestLanguages(r *http.Request) []string {
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		return locale.ParseAcceptLanguage(lang)
+	}
+	return locale.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+}
+
+// setLanguageHeaders marks a response that depends on the client's languages
+// served is the language of the response, empty when it mixes languages
+func setLanguageHeaders(w http.ResponseWriter, languages []string, served string) {
+	w.Header().Add("Vary", "Accept-Language")
+	if len(languages) > 0 && served != "" {
+		w.Header().Set("Content-Language", served)
+	}
+}
+
 func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
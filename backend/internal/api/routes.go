@@ -9,11 +9,59 @@ import (
 func SetupRoutes(mux *http.ServeMux, handlers *Handlers, cfg *config.Config) {
 	// API routes
 	mux.HandleFunc("GET /api/catalog", handlers.GetCatalog)
+	mux.HandleFunc("GET /api/search", handlers.SearchCards)
+	mux.HandleFunc("POST /api/search", handlers.SearchCards)
+	mux.HandleFunc("POST /api/decks", handlers.CreateDeck)
+	mux.HandleFunc("POST /api/decks/draft", handlers.DraftDeck)
 	mux.HandleFunc("GET /api/decks/{id}/preview", handlers.GetDeckPreview)
 	mux.HandleFunc("GET /api/decks/{id}", handlers.GetDeck)
 	mux.HandleFunc("POST /api/decks/{id}/generate", handlers.GenerateDeck)
+	mux.HandleFunc("POST /api/decks/{id}/generate/estimate", handlers.EstimateGeneration)
+	mux.HandleFunc("POST /api/decks/{id}/generate/cancel", handlers.CancelGeneration)
+	mux.HandleFunc("POST /api/decks/{id}/generate/pause", handlers.PauseGeneration)
+	mux.HandleFunc("POST /api/decks/{id}/generate/resume", handlers.ResumeGeneration)
 	mux.HandleFunc("GET /api/decks/{id}/status", handlers.GetGenerateStatus)
+	mux.HandleFunc("GET /api/decks/{id}/events", handlers.StreamEvents)
 	mux.HandleFunc("POST /api/decks/{id}/download", handlers.DownloadDeck)
+	mux.HandleFunc("GET /api/decks/{id}/cards/{cardId}/prompt", handlers.PreviewImagePrompt)
+	mux.HandleFunc("GET /api/decks/{id}/readings", handlers.ValidateReadings)
+
+	// Image candidate review
+	mux.HandleFunc("GET /api/decks/{id}/candidates", handlers.ListCandidates)
+	mux.HandleFunc("POST /api/decks/{id}/cards/{cardId}/candidates/{candidateId}/select", handlers.SelectCandidate)
+	mux.HandleFunc("POST /api/decks/{id}/cards/{cardId}/candidates/reject", handlers.RejectCandidates)
+	mux.HandleFunc("PUT /api/decks/{id}/cards/{cardId}/image", handlers.ReplaceImage)
+
+	// Example sentences, drafted by the language model and approved by editors
+	mux.HandleFunc("POST /api/decks/{id}/cards/{cardId}/examples/generate", handlers.GenerateExamples)
+	mux.HandleFunc("PUT /api/decks/{id}/cards/{cardId}/examples/{exampleId}", handlers.UpdateExample)
+	mux.HandleFunc("DELETE /api/decks/{id}/cards/{cardId}/examples/{exampleId}", handlers.DeleteExample)
+
+	// Machine translation of back texts, cards stay marked until reviewed
+	mux.HandleFunc("POST /api/decks/{id}/translations", handlers.TranslateDeck)
+	mux.HandleFunc("POST /api/decks/{id}/clone", handlers.CloneDeck)
+	mux.HandleFunc("POST /api/decks/{id}/cards/{cardId}/review", handlers.ReviewCard)
+
+	// Art style presets
+	mux.HandleFunc("GET /api/styles", handlers.ListStyles)
+	mux.HandleFunc("POST /api/styles", handlers.CreateStyle)
+	mux.HandleFunc("GET /api/styles/{id}", handlers.GetStyle)
+	mux.HandleFunc("PUT /api/styles/{id}", handlers.UpdateStyle)
+	mux.HandleFunc("DELETE /api/styles/{id}", handlers.DeleteStyle)
+
+	// TTS voices and supported languages for deck editors
+	mux.HandleFunc("GET /api/tts/voices", handlers.ListVoices)
+	mux.HandleFunc("GET /api/languages", handlers.ListLanguages)
+
+	// Outbound webhooks
+	mux.HandleFunc("GET /api/webhooks", handlers.ListWebhooks)
+	mux.HandleFunc("POST /api/webhooks", handlers.CreateWebhook)
+	mux.HandleFunc("DELETE /api/webhooks/{id}", handlers.DeleteWebhook)
+	mux.HandleFunc("GET /api/webhooks/deliveries", handlers.ListWebhookDeliveries)
+	mux.HandleFunc("POST /api/webhooks/deliveries/{id}/redeliver", handlers.RedeliverWebhook)
+
+	// Provider usage and cost reporting
+	mux.HandleFunc("GET /api/usage", handlers.GetUsage)
 
 	// IAP receipt verification
 	mux.HandleFunc("POST /api/receipts/verify", handlers.VerifyReceipt)
@@ -1,15 +1,77 @@
 package config
 
 import (
+	"log"
 	"os"
+	"path/filepath"
+	"strconv"
 )
 
 type Config struct {
-	Port            string
-	ElevenLabsKey   string
-	GoogleAPIKey    string
-	StoragePath     string
-	StorageBaseURL  string
+	Port              string
+	ElevenLabsKey     string
+	ElevenLabsVoiceID string
+	GoogleAPIKey      string
+	StoragePath       string
+	StorageBaseURL    string
+	DataPath          string // Internal state, kept apart from the publicly served StoragePath
+
+	// Audio post-processing (silence trimming + loudness normalization)
+	AudioPostProcess        bool
+	AudioEncoder            string
+	AudioTargetLUFS         float64
+	AudioSilenceThresholdDB float64
+
+	// Image renditions (thumb/card/full) instead of the raw provider PNG
+	ImageRenditions bool
+
+	// Provider rate limits, shared by all generation jobs
+	ElevenLabsRate           float64 // requests per second, 0 = unlimited
+	ElevenLabsBurst          int
+	ElevenLabsMaxConcurrency int
+	ImagenRate               float64
+	ImagenBurst              int
+	ImagenMaxConcurrency     int
+	ProviderMaxRetries       int // retries after 429 Too Many Requests
+
+	// Language model for deck authoring: openai (any compatible endpoint) or ollama
+	LLMProvider string
+	LLMBaseURL  string
+	LLMAPIKey   string
+	LLMModel    string
+
+	// Machine translation for cloning decks: deepl, libretranslate or pseudo
+	TranslateProvider string
+	TranslateBaseURL  string
+	TranslateAPIKey   string
+
+	// Language registry overrides (JSON array), empty uses the built-in languages
+	LanguagesFile string
+
+	// Transliteration dictionaries (surface<TAB>reading), empty disables them
+	TranslitJapaneseDict string
+	TranslitChineseDict  string
+
+	// Provider HTTP mode: live, record or replay
+	// Recordings let development and tests run offline without credentials
+	RecordingsPath     string
+	ElevenLabsHTTPMode string
+	ImagenHTTPMode     string
+	AppleHTTPMode      string
+	LLMHTTPMode        string
+	TranslateHTTPMode  string
+
+	// Generation pricing and budgets (USD), a budget of 0 means no limit
+	TTSCostPer1KChars float64
+	ImageCost         float64
+	JobBudget         float64
+	MonthlyBudget     float64
+
+	// Outbound webhooks
+	WebhookMaxAttempts int
+
+	// Bearer token for editor requests, empty disables editor access
+	EditorToken string
 
 	// IAP validation
 	AppleSharedSecret string
@@ -20,11 +82,69 @@ type Config struct {
 
 func Load() *Config {
 	return &Config{
-		Port:            getEnv("PORT", "8080"),
-		ElevenLabsKey:   getEnv("ELEVENLABS_API_KEY", ""),
-		GoogleAPIKey:    getEnv("GOOGLE_API_KEY", ""),
-		StoragePath:     getEnv("STORAGE_PATH", "./media"),
-		StorageBaseURL:  getEnv("STORAGE_BASE_URL", "http://localhost:8080/media"),
+		Port:              getEnv("PORT", "8080"),
+		ElevenLabsKey:     getEnv("ELEVENLABS_API_KEY", ""),
+		ElevenLabsVoiceID: getEnv("ELEVENLABS_VOICE_ID", ""),
+		GoogleAPIKey:      getEnv("GOOGLE_API_KEY", ""),
+		StoragePath:       getEnv("STORAGE_PATH", "./media"),
+		StorageBaseURL:    getEnv("STORAGE_BASE_URL", "http://localhost:8080/media"),
+		DataPath:          getEnv("DATA_PATH", "./data"),
+
+		// Audio post-processing
+		AudioPostProcess:        getEnv("AUDIO_POSTPROCESS", "false") == "true",
+		AudioEncoder:            getEnv("AUDIO_ENCODER", "mp3"),
+		AudioTargetLUFS:         getEnvFloat("AUDIO_TARGET_LUFS", -16),
+		AudioSilenceThresholdDB: getEnvFloat("AUDIO_SILENCE_THRESHOLD_DB", -50),
+
+		// Images
+		ImageRenditions: getEnv("IMAGE_RENDITIONS", "true") == "true",
+
+		// Provider rate limits
+		ElevenLabsRate:           getEnvFloat("ELEVENLABS_RATE", 2),
+		ElevenLabsBurst:          getEnvInt("ELEVENLABS_BURST", 2),
+		ElevenLabsMaxConcurrency: getEnvInt("ELEVENLABS_MAX_CONCURRENCY", 2),
+		ImagenRate:               getEnvFloat("IMAGEN_RATE", 0.5),
+		ImagenBurst:              getEnvInt("IMAGEN_BURST", 1),
+		ImagenMaxConcurrency:     getEnvInt("IMAGEN_MAX_CONCURRENCY", 1),
+		ProviderMaxRetries:       getEnvInt("PROVIDER_MAX_RETRIES", 3),
+
+		// Deck authoring
+		LLMProvider: getEnv("LLM_PROVIDER", ""),
+		LLMBaseURL:  getEnv("LLM_BASE_URL", ""),
+		LLMAPIKey:   getEnv("LLM_API_KEY", ""),
+		LLMModel:    getEnv("LLM_MODEL", ""),
+
+		// Machine translation
+		TranslateProvider: getEnv("TRANSLATE_PROVIDER", ""),
+		TranslateBaseURL:  getEnv("TRANSLATE_BASE_URL", ""),
+		TranslateAPIKey:   getEnv("TRANSLATE_API_KEY", ""),
+
+		// Languages
+		LanguagesFile: getEnv("LANGUAGES_FILE", ""),
+
+		// Transliteration
+		TranslitJapaneseDict: getEnv("TRANSLIT_JA_DICT", "./dict/ja.tsv"),
+		TranslitChineseDict:  getEnv("TRANSLIT_ZH_DICT", ""),
+
+		// Record/replay
+		RecordingsPath:     getEnv("RECORDINGS_PATH", "./recordings"),
+		ElevenLabsHTTPMode: getEnv("ELEVENLABS_HTTP_MODE", "live"),
+		ImagenHTTPMode:     getEnv("IMAGEN_HTTP_MODE", "live"),
+		AppleHTTPMode:      getEnv("APPLE_HTTP_MODE", "live"),
+		LLMHTTPMode:        getEnv("LLM_HTTP_MODE", "live"),
+		TranslateHTTPMode:  getEnv("TRANSLATE_HTTP_MODE", "live"),
+
+		// Pricing and budgets
+		TTSCostPer1KChars: getEnvFloat("TTS_COST_PER_1K_CHARS", 0.30),
+		ImageCost:         getEnvFloat("IMAGE_COST", 0.04),
+		JobBudget:         getEnvFloat("GENERATION_JOB_BUDGET", 0),
+		MonthlyBudget:     getEnvFloat("GENERATION_MONTHLY_BUDGET", 0),
+
+		// Webhooks
+		WebhookMaxAttempts: getEnvInt("WEBHOOK_MAX_ATTEMPTS", 5),
+
+		// Editors
+		EditorToken: getEnv("EDITOR_TOKEN", ""),
 
 		// IAP
 		AppleSharedSecret: getEnv("APPLE_SHARED_SECRET", ""),
@@ -34,9 +154,50 @@ func Load() *Config {
 	}
 }
 
+// StatePath returns the directory for the internal state called name under DataPath
+// A directory left under StoragePath by older versions is moved there, since
+// everything under StoragePath is served publicly
+func (c *Config) StatePath(name string) string {
+	path := filepath.Join(c.DataPath, name)
+	legacy := filepath.Join(c.StoragePath, name)
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return path
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		return path
+	}
+	if err := os.MkdirAll(c.DataPath, 0755); err != nil {
+		log.Printf("Failed to create data directory: %v", err)
+		return path
+	}
+	if err := os.Rename(legacy, path); err != nil {
+		log.Printf("Failed to move %s to %s, move it by hand: %v", legacy, path, err)
+	}
+	return path
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
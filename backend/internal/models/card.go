@@ -1,20 +1,117 @@
 package models
 
+import "time"
+
 type Media struct {
 	Image      string `json:"image,omitempty"`
 	AudioFront string `json:"audioFront,omitempty"`
 	AudioBack  string `json:"audioBack,omitempty"`
 	Video      string `json:"video,omitempty"`
+
+	// AudioVariants holds extra front audio renditions keyed by variant name (e.g. "slow")
+	AudioVariants map[string]string `json:"audioVariants,omitempty"`
+
+	// AudioDurationsMs holds post-processed audio lengths keyed by "front" or variant name
+	AudioDurationsMs map[string]int64 `json:"audioDurationsMs,omitempty"`
+
+	// ImageRenditions holds resized copies of Image keyed by name (thumb, card, full)
+	ImageRenditions map[string]ImageRendition `json:"imageRenditions,omitempty"`
+
+	// Placeholder data shown while Image downloads
+	ImageWidth    int    `json:"imageWidth,omitempty"`
+	ImageHeight   int    `json:"imageHeight,omitempty"`
+	BlurHash      string `json:"blurHash,omitempty"`
+	DominantColor string `json:"dominantColor,omitempty"` // "#rrggbb"
+}
+
+// ImageRendition is one stored size of a card image
+type ImageRendition struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
 }
 
 type Card struct {
-	ID          string  `json:"id"`
-	FrontText   string  `json:"frontText"`
-	BackText    string  `json:"backText"`
-	Reading     string  `json:"reading,omitempty"`
-	Priority    int     `json:"priority"`
-	Media       *Media  `json:"media,omitempty"`
-	MediaStatus string  `json:"mediaStatus,omitempty"` // pending, generating, ready, error
+	ID        string   `json:"id"`
+	FrontText string   `json:"frontText"`
+	BackText  string   `json:"backText"`
+	Reading   string   `json:"reading,omitempty"`
+	Priority  int      `json:"priority"`
+	Tags      []string `json:"tags,omitempty"`
+
+	// Translations holds backText in other languages keyed by BCP-47 tag;
+	// backText itself is in the deck's backLanguage
+	Translations map[string]string `json:"translations,omitempty"`
+
+	// NeedsReview marks machine-translated back text or translations waiting for an editor
+	NeedsReview bool `json:"needsReview,omitempty"`
+
+	// Furigana splits frontText into kanji runs with their reading, computed on save
+	Furigana []RubySegment `json:"furigana,omitempty"`
+
+	Media       *Media `json:"media,omitempty"`
+	MediaStatus string `json:"mediaStatus,omitempty"` // pending, generating, ready, error
+
+	// Image prompt overrides, rendered like Deck.ImagePromptTemplate
+	ImagePrompt    string `json:"imagePrompt,omitempty"`
+	NegativePrompt string `json:"negativePrompt,omitempty"`
+
+	// ImageCandidates are generated images waiting for editor review
+	ImageCandidates []ImageCandidate `json:"imageCandidates,omitempty"`
+
+	// Examples show the word in context, only approved ones are synthesized
+	Examples []Example `json:"examples,omitempty"`
+}
+
+// Example is a sentence using the card word
+type Example struct {
+	ID          string `json:"id"`
+	Text        string `json:"text"`              // Sentence in frontLanguage
+	Reading     string `json:"reading,omitempty"` // Romanized pronunciation for non-Latin scripts
+	Translation string `json:"translation"`       // Sentence in backLanguage
+	AudioURL    string `json:"audioUrl,omitempty"`
+	Approved    bool   `json:"approved"` // Set by an editor after review
+
+	// Translations holds the sentence in other back languages keyed by BCP-47 tag
+	Translations map[string]string `json:"translations,omitempty"`
+}
+
+// ExampleUpdate edits an example, nil fields are left unchanged
+type ExampleUpdate struct {
+	Text        *string `json:"text,omitempty"`
+	Reading     *string `json:"reading,omitempty"`
+	Translation *string `json:"translation,omitempty"`
+	Approved    *bool   `json:"approved,omitempty"`
+}
+
+// CardReview accepts a machine-translated card, a nil backText keeps the translation
+type CardReview struct {
+	BackText *string `json:"backText,omitempty"`
+	Language string  `json:"language,omitempty"` // Translation that backText corrects, defaults to the deck's backLanguage
+}
+
+// RubySegment is a run of frontText, kanji runs carry their kana reading
+type RubySegment struct {
+	Base string `json:"base"`
+	Ruby string `json:"ruby,omitempty"`
+}
+
+// ReadingIssue flags a card whose reading is missing or differs from the computed one
+type ReadingIssue struct {
+	CardID    string `json:"cardId"`
+	FrontText string `json:"frontText"`
+	Reading   string `json:"reading,omitempty"`
+	Computed  string `json:"computed,omitempty"`
+	Problem   string `json:"problem"`          // missing, mismatch, unavailable
+	Detail    string `json:"detail,omitempty"` // Why no reading could be computed
+}
+
+// ImageCandidate is one generated image an editor can pick for a card
+type ImageCandidate struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Prompt    string    `json:"prompt"`
+	CreatedAt time.Time `json:"createdAt"`
 }
 
 type CardInput struct {
@@ -22,4 +119,5 @@ type CardInput struct {
 	FrontText string `json:"frontText"`
 	BackText  string `json:"backText"`
 	Reading   string `json:"reading,omitempty"`
+	Priority  int    `json:"priority,omitempty"`
 }
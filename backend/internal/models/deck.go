@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type Deck struct {
 	ID            string `json:"id"`
 	Name          string `json:"name"`
@@ -8,10 +10,82 @@ type Deck struct {
 	BackLanguage  string `json:"backLanguage"`
 	Cards         []Card `json:"cards"`
 	MediaBaseURL  string `json:"mediaBaseUrl,omitempty"`
+	ThumbnailURL  string `json:"thumbnailUrl,omitempty"`
+
+	// Store listing
+	Level     string    `json:"level,omitempty"` // e.g. "A1" or "beginner"
+	Tags      []string  `json:"tags,omitempty"`  // e.g. "travel", "food"
+	CreatedAt time.Time `json:"createdAt,omitzero"`
+
+	// Localizations holds store metadata keyed by BCP-47 tag; name, description
+	// and thumbnailUrl above are in backLanguage and used as the last fallback
+	Localizations map[string]DeckLocalization `json:"localizations,omitempty"`
+
+	// BackLanguages lists backLanguage and the translations every card has, set in responses
+	BackLanguages []string `json:"backLanguages,omitempty"`
 
 	// Media generation settings
-	ImagePromptTemplate string `json:"imagePromptTemplate,omitempty"` // e.g. "Simple illustration of {word}, flat style"
-	TTSVoiceID          string `json:"ttsVoiceId,omitempty"`          // ElevenLabs voice ID for frontLanguage
+	ImagePromptTemplate string         `json:"imagePromptTemplate,omitempty"` // e.g. "Simple illustration of {word}, flat style"
+	StyleID             string         `json:"styleId,omitempty"`             // Art style preset shared across decks
+	ImageCandidates     int            `json:"imageCandidates,omitempty"`     // >1 generates this many images per card for editor review
+	TTSVoiceID          string         `json:"ttsVoiceId,omitempty"`          // ElevenLabs voice ID for frontLanguage
+	TTSBackVoiceID      string         `json:"ttsBackVoiceId,omitempty"`      // Voice for backLanguage, defaults to ttsVoiceId
+	BackAudio           bool           `json:"backAudio,omitempty"`           // Also synthesize backText on full runs
+	TTSUseReading       bool           `json:"ttsUseReading,omitempty"`       // Synthesize from card reading instead of frontText
+	AudioVariants       []AudioVariant `json:"audioVariants,omitempty"`       // Extra audio renditions, e.g. slow playback
+	TTSSettings         *TTSSettings   `json:"ttsSettings,omitempty"`         // Provider tuning, defaults used when nil
+}
+
+// TTSSettings tunes the TTS provider for a deck; nil fields use provider defaults
+type TTSSettings struct {
+	ModelID         string   `json:"modelId,omitempty"`         // e.g. "eleven_multilingual_v2"
+	Stability       *float64 `json:"stability,omitempty"`       // 0-1
+	SimilarityBoost *float64 `json:"similarityBoost,omitempty"` // 0-1
+	Style           *float64 `json:"style,omitempty"`           // 0-1
+	UseSpeakerBoost *bool    `json:"useSpeakerBoost,omitempty"`
+	OutputFormat    string   `json:"outputFormat,omitempty"` // codec_samplerate_bitrate, e.g. "mp3_44100_128"
+}
+
+// AudioVariant describes an additional front audio rendition generated per card
+type AudioVariant struct {
+	Name  string  `json:"name"`  // Key in Media.AudioVariants, e.g. "slow"
+	Speed float64 `json:"speed"` // Playback speed multiplier, e.g. 0.7
+}
+
+// DeckDraftRequest asks the language model to draft a deck
+type DeckDraftRequest struct {
+	FrontLanguage string `json:"frontLanguage"` // Language being learned
+	BackLanguage  string `json:"backLanguage"`  // Learner's language
+	Topic         string `json:"topic"`         // e.g. "ordering food in a restaurant"
+	Level         string `json:"level"`         // e.g. "A1" or "beginner"
+	CardCount     int    `json:"cardCount"`     // Defaults to 20
+}
+
+// DeckDraft is an unsaved deck for editor review, Deck can be passed to CreateDeck
+type DeckDraft struct {
+	Deck     Deck        `json:"deck"`
+	Cards    []CardInput `json:"cards"`              // Drafted entries as returned by the model
+	Model    string      `json:"model"`              // Model that drafted the deck
+	Warnings []string    `json:"warnings,omitempty"` // Entries dropped or adjusted during validation
+}
+
+// DeckLocalization is store metadata in one locale, empty fields fall back
+// to the next locale the user accepts
+type DeckLocalization struct {
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+	ThumbnailURL string `json:"thumbnailUrl,omitempty"` // e.g. cover art with localized title
+}
+
+// DeckTranslateRequest machine-translates a deck's cards into another back language
+type DeckTranslateRequest struct {
+	Language string `json:"language"`
+}
+
+// DeckCloneRequest copies a deck into another back language
+type DeckCloneRequest struct {
+	BackLanguage string `json:"backLanguage"`
+	ID           string `json:"id,omitempty"` // Defaults to "<source id>-<backLanguage>"
 }
 
 type CatalogItem struct {
@@ -23,10 +97,19 @@ type CatalogItem struct {
 	IAPProductID string   `json:"iapProductId,omitempty"`
 	ThumbnailURL string   `json:"thumbnailUrl,omitempty"`
 	Languages    []string `json:"languages"`
+
+	Level     string    `json:"level,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	CreatedAt time.Time `json:"createdAt,omitzero"`
+
+	// BackLanguages lists every language the cards can be shown in
+	BackLanguages []string `json:"backLanguages"`
 }
 
 type Catalog struct {
-	Decks []CatalogItem `json:"decks"`
+	Decks      []CatalogItem `json:"decks"`
+	Total      int           `json:"total"`                // Decks matching the query across all pages
+	NextCursor string        `json:"nextCursor,omitempty"` // Empty on the last page
 }
 
 type DeckPreview struct {
@@ -37,17 +120,52 @@ type DeckPreview struct {
 	BackLanguage  string `json:"backLanguage"`
 	TotalCards    int    `json:"totalCards"`
 	PreviewCards  []Card `json:"previewCards"` // 3-5 sample cards
+	ThumbnailURL  string `json:"thumbnailUrl,omitempty"`
+
+	BackLanguages []string `json:"backLanguages"`
 }
 
+// Asset kinds accepted by GenerateRequest.Assets
+const (
+	AssetAudioFront = "audioFront"
+	AssetAudioBack  = "audioBack"
+	AssetImage      = "image"
+	AssetExamples   = "examples" // Drafts examples for cards without any, synthesizes approved ones
+)
+
 type GenerateRequest struct {
-	DeckID string      `json:"deckId"`
-	Cards  []CardInput `json:"cards,omitempty"` // Optional: specific cards to generate
+	DeckID      string      `json:"deckId"`
+	Cards       []CardInput `json:"cards,omitempty"`       // Optional: specific cards to generate (matched by ID)
+	CardIDs     []string    `json:"cardIds,omitempty"`     // Optional: specific card IDs to generate
+	Assets      []string    `json:"assets,omitempty"`      // Optional: audioFront, audioBack, image, examples
+	MissingOnly bool        `json:"missingOnly,omitempty"` // Skip assets whose files already exist
+}
+
+type PromptPreview struct {
+	DeckID           string `json:"deckId"`
+	CardID           string `json:"cardId"`
+	StyleID          string `json:"styleId,omitempty"`
+	Prompt           string `json:"prompt"`
+	NegativePrompt   string `json:"negativePrompt,omitempty"`
+	AspectRatio      string `json:"aspectRatio,omitempty"`
+	PersonGeneration string `json:"personGeneration,omitempty"`
+}
+
+// CandidateReview lists a card whose image candidates are waiting for review
+type CandidateReview struct {
+	CardID     string           `json:"cardId"`
+	FrontText  string           `json:"frontText"`
+	BackText   string           `json:"backText"`
+	Image      string           `json:"image,omitempty"` // Currently selected image
+	Candidates []ImageCandidate `json:"candidates"`
 }
 
 type GenerateStatus struct {
-	DeckID     string `json:"deckId"`
-	Status     string `json:"status"` // pending, generating, completed, error
-	Progress   int    `json:"progress"`
-	TotalCards int    `json:"totalCards"`
-	Error      string `json:"error,omitempty"`
+	DeckID     string  `json:"deckId"`
+	Status     string  `json:"status"` // pending, generating, paused, cancelled, completed, error
+	Progress   int     `json:"progress"`
+	TotalCards int     `json:"totalCards"`
+	Failures   int     `json:"failures,omitempty"` // Assets that failed to generate
+	Cost       float64 `json:"cost"`               // Provider cost so far, USD
+	Error      string  `json:"error,omitempty"`
 }
@@ -0,0 +1,27 @@
+package models
+
+// SearchResults are cards matching a query, grouped by deck in ranking order
+type SearchResults struct {
+	Query string     `json:"query"`
+	Total int        `json:"total"` // Matching cards, including those past the limit
+	Decks []DeckHits `json:"decks"`
+}
+
+// DeckHits are the matching cards of one deck
+// Locked decks are paid: hits carry only the front text they matched
+type DeckHits struct {
+	DeckID        string      `json:"deckId"`
+	Name          string      `json:"name"`
+	FrontLanguage string      `json:"frontLanguage"`
+	BackLanguage  string      `json:"backLanguage"`
+	Locked        bool        `json:"locked,omitempty"`
+	Hits          []SearchHit `json:"hits"`
+}
+
+type SearchHit struct {
+	CardID    string   `json:"cardId"`
+	FrontText string   `json:"frontText"`
+	BackText  string   `json:"backText,omitempty"`
+	Reading   string   `json:"reading,omitempty"`
+	Matched   []string `json:"matched"` // frontText, backText (including translations), reading
+}
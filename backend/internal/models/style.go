@@ -0,0 +1,15 @@
+package models
+
+// StylePreset is a reusable art style shared across decks
+type StylePreset struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Description    string `json:"description,omitempty"`
+	PromptPrefix   string `json:"promptPrefix,omitempty"`   // Rendered before the deck/card prompt
+	PromptSuffix   string `json:"promptSuffix,omitempty"`   // Rendered after the deck/card prompt
+	NegativePrompt string `json:"negativePrompt,omitempty"` // Combined with card negative prompts
+
+	// Image provider parameters
+	AspectRatio      string `json:"aspectRatio,omitempty"`      // e.g. "1:1", "4:3"
+	PersonGeneration string `json:"personGeneration,omitempty"` // dont_allow, allow_adult, allow_all
+}
@@ -0,0 +1,21 @@
+package models
+
+// CostEstimate is the dry-run cost of a generation request
+type CostEstimate struct {
+	DeckID        string  `json:"deckId"`
+	Cards         int     `json:"cards"`
+	TTSCharacters int64   `json:"ttsCharacters"`
+	TTSRequests   int     `json:"ttsRequests"`
+	Images        int     `json:"images"`
+	TTSCost       float64 `json:"ttsCost"`
+	ImageCost     float64 `json:"imageCost"`
+	TotalCost     float64 `json:"totalCost"`
+	Currency      string  `json:"currency"`
+
+	// Budgets from config, 0 means no limit
+	JobBudget     float64 `json:"jobBudget,omitempty"`
+	MonthlyBudget float64 `json:"monthlyBudget,omitempty"`
+	MonthToDate   float64 `json:"monthToDate"`
+	WithinBudget  bool    `json:"withinBudget"`
+	BudgetError   string  `json:"budgetError,omitempty"`
+}
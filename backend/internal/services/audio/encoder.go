@@ -0,0 +1,88 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Encoder writes processed PCM into a storable file format
+type Encoder interface {
+	Encode(pcm *PCM) ([]byte, error)
+	Extension() string
+}
+
+// KeepMP3 names no encoder: the provider's MP3 is stored as it is
+// There is no built-in MP3 encoder and WAV is about ten times larger, so
+// processing only happens when an encoder is configured explicitly
+const KeepMP3 = "mp3"
+
+var encoders = map[string]Encoder{
+	"wav": WAVEncoder{},
+}
+
+// RegisterEncoder makes an encoder available to NewEncoder under name
+// Use it to plug in formats such as Opus that need external codecs
+func RegisterEncoder(name string, encoder Encoder) {
+	encoders[name] = encoder
+}
+
+// NewEncoder returns the encoder registered under name
+func NewEncoder(name string) (Encoder, error) {
+	encoder, ok := encoders[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown audio encoder: %s", name)
+	}
+	return encoder, nil
+}
+
+// WAVEncoder writes 16-bit PCM RIFF/WAVE files, uncompressed at about 88 KB/s for mono speech
+type WAVEncoder struct{}
+
+func (WAVEncoder) Extension() string {
+	return ".wav"
+}
+
+func (WAVEncoder) Encode(pcm *PCM) ([]byte, error) {
+	if pcm.Channels == 0 || pcm.SampleRate == 0 {
+		return nil, fmt.Errorf("invalid pcm format: %d channels at %d Hz", pcm.Channels, pcm.SampleRate)
+	}
+
+	const bitsPerSample = 16
+	blockAlign := pcm.Channels * bitsPerSample / 8
+	dataSize := len(pcm.Samples) * 2
+
+	var buf bytes.Buffer
+	buf.Grow(44 + dataSize)
+
+	header := []interface{}{
+		[4]byte{'R', 'I', 'F', 'F'},
+		uint32(36 + dataSize),
+		[4]byte{'W', 'A', 'V', 'E'},
+		[4]byte{'f', 'm', 't', ' '},
+		uint32(16), // fmt chunk size
+		uint16(1),  // PCM
+		uint16(pcm.Channels),
+		uint32(pcm.SampleRate),
+		uint32(pcm.SampleRate * blockAlign), // byte rate
+		uint16(blockAlign),
+		uint16(bitsPerSample),
+		[4]byte{'d', 'a', 't', 'a'},
+		uint32(dataSize),
+	}
+	for _, field := range header {
+		if err := binary.Write(&buf, binary.LittleEndian, field); err != nil {
+			return nil, err
+		}
+	}
+
+	sample := make([]byte, 2)
+	for _, s := range pcm.Samples {
+		v := math.Max(-1, math.Min(1, s))
+		binary.LittleEndian.PutUint16(sample, uint16(int16(math.Round(v*32767))))
+		buf.Write(sample)
+	}
+
+	return buf.Bytes(), nil
+}
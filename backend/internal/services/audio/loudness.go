@@ -0,0 +1,131 @@
+package audio
+
+import "math"
+
+// Loudness measurement following ITU-R BS.1770-4 (K-weighting + gating)
+
+const (
+	blockDuration    = 0.4  // 400ms gating blocks
+	blockOverlap     = 0.75 // 75% overlap between blocks
+	absoluteGateLUFS = -70.0
+	relativeGateLU   = -10.0
+)
+
+// biquad is a second order IIR filter in direct form I
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// kWeighting returns the pre-filter (high shelf) and RLB (high pass) stages
+// Coefficients are derived for the given sample rate as in libebur128
+func kWeighting(sampleRate int) (*biquad, *biquad) {
+	rate := float64(sampleRate)
+
+	f0 := 1681.974450955533
+	g := 3.999843853973347
+	q := 0.7071752369554196
+	k := math.Tan(math.Pi * f0 / rate)
+	vh := math.Pow(10, g/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+	shelf := &biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	f0 = 38.13547087602444
+	q = 0.5003270373238773
+	k = math.Tan(math.Pi * f0 / rate)
+	a0 = 1 + k/q + k*k
+	highPass := &biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	return shelf, highPass
+}
+
+// IntegratedLoudness returns the gated loudness of pcm in LUFS
+// Returns -Inf for silent input
+func IntegratedLoudness(pcm *PCM) float64 {
+	frames := pcm.Frames()
+	if frames == 0 {
+		return math.Inf(-1)
+	}
+
+	// K-weighted squared samples summed over channels (all channel weights are 1 for mono/stereo)
+	weighted := make([]float64, frames)
+	for ch := 0; ch < pcm.Channels; ch++ {
+		shelf, highPass := kWeighting(pcm.SampleRate)
+		for i := 0; i < frames; i++ {
+			y := highPass.process(shelf.process(pcm.Samples[i*pcm.Channels+ch]))
+			weighted[i] += y * y
+		}
+	}
+
+	blockSize := int(blockDuration * float64(pcm.SampleRate))
+	step := int(float64(blockSize) * (1 - blockOverlap))
+	if blockSize > frames {
+		// Clip shorter than one block, measure it as a single block
+		blockSize = frames
+		step = frames
+	}
+
+	var blocks []float64
+	for start := 0; start+blockSize <= frames; start += step {
+		var sum float64
+		for _, v := range weighted[start : start+blockSize] {
+			sum += v
+		}
+		blocks = append(blocks, sum/float64(blockSize))
+	}
+
+	gated := gate(blocks, math.Inf(-1), absoluteGateLUFS)
+	if len(gated) == 0 {
+		return math.Inf(-1)
+	}
+	relative := loudnessOf(mean(gated)) + relativeGateLU
+	gated = gate(gated, relative, absoluteGateLUFS)
+	if len(gated) == 0 {
+		return math.Inf(-1)
+	}
+
+	return loudnessOf(mean(gated))
+}
+
+func gate(blocks []float64, relative, absolute float64) []float64 {
+	var kept []float64
+	for _, b := range blocks {
+		l := loudnessOf(b)
+		if l > absolute && l > relative {
+			kept = append(kept, b)
+		}
+	}
+	return kept
+}
+
+func loudnessOf(meanSquare float64) float64 {
+	return -0.691 + 10*math.Log10(meanSquare)
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,218 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/go-mp3"
+)
+
+// PCM holds decoded interleaved audio with samples in the range [-1, 1]
+type PCM struct {
+	SampleRate int
+	Channels   int
+	Samples    []float64
+}
+
+// Frames returns the number of samples per channel
+func (p *PCM) Frames() int {
+	if p.Channels == 0 {
+		return 0
+	}
+	return len(p.Samples) / p.Channels
+}
+
+// Duration returns the playback length of the audio
+func (p *PCM) Duration() time.Duration {
+	if p.SampleRate == 0 {
+		return 0
+	}
+	return time.Duration(p.Frames()) * time.Second / time.Duration(p.SampleRate)
+}
+
+// DecodeMP3 decodes MP3 data into PCM
+// The decoder always produces 16-bit stereo output
+func DecodeMP3(data []byte) (*PCM, error) {
+	decoder, err := mp3.NewDecoder(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mp3: %w", err)
+	}
+
+	raw, err := io.ReadAll(decoder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode mp3: %w", err)
+	}
+
+	samples := make([]float64, len(raw)/2)
+	for i := range samples {
+		v := int16(binary.LittleEndian.Uint16(raw[i*2:]))
+		samples[i] = float64(v) / 32768
+	}
+
+	return &PCM{
+		SampleRate: decoder.SampleRate(),
+		Channels:   2,
+		Samples:    samples,
+	}, nil
+}
+
+// MP3Duration returns the playback length of MP3 data without decoding the samples
+func MP3Duration(data []byte) (time.Duration, error) {
+	decoder, err := mp3.NewDecoder(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("failed to open mp3: %w", err)
+	}
+	if decoder.SampleRate() == 0 || decoder.Length() < 0 {
+		return 0, fmt.Errorf("unknown mp3 length")
+	}
+
+	// Length is in bytes of 16-bit stereo output
+	frames := decoder.Length() / 4
+	return time.Duration(frames) * time.Second / time.Duration(decoder.SampleRate()), nil
+}
+
+// Options configures the post-processing steps
+type Options struct {
+	SilenceThresholdDB float64       // Frames quieter than this (dBFS) count as silence, e.g. -50
+	SilencePadding     time.Duration // Silence kept around the trimmed audio
+	TargetLUFS         float64       // Integrated loudness target, e.g. -16
+	PeakCeilingDB      float64       // Maximum sample peak after normalization (dBFS), e.g. -1
+}
+
+// Result is the output of a processing run
+type Result struct {
+	Data      []byte
+	Extension string // e.g. ".wav"
+	Duration  time.Duration
+}
+
+// Processor trims and normalizes generated speech before it is stored
+type Processor struct {
+	opts    Options
+	encoder Encoder
+}
+
+// NewProcessor creates a processor that writes its output with encoder
+func NewProcessor(opts Options, encoder Encoder) *Processor {
+	return &Processor{
+		opts:    opts,
+		encoder: encoder,
+	}
+}
+
+// Process decodes MP3 data, trims silence, normalizes loudness and re-encodes it
+func (p *Processor) Process(mp3Data []byte) (*Result, error) {
+	pcm, err := DecodeMP3(mp3Data)
+	if err != nil {
+		return nil, err
+	}
+
+	DownmixMono(pcm)
+	TrimSilence(pcm, p.opts.SilenceThresholdDB, p.opts.SilencePadding)
+	Normalize(pcm, p.opts.TargetLUFS, p.opts.PeakCeilingDB)
+
+	data, err := p.encoder.Encode(pcm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audio: %w", err)
+	}
+
+	return &Result{
+		Data:      data,
+		Extension: p.encoder.Extension(),
+		Duration:  pcm.Duration(),
+	}, nil
+}
+
+// DownmixMono collapses stereo audio whose channels are identical to mono
+// The MP3 decoder duplicates mono sources such as TTS output into two channels,
+// which would double the size of the encoded file for no gain
+func DownmixMono(pcm *PCM) {
+	if pcm.Channels != 2 {
+		return
+	}
+	for i := 0; i+1 < len(pcm.Samples); i += 2 {
+		if pcm.Samples[i] != pcm.Samples[i+1] {
+			return
+		}
+	}
+
+	mono := make([]float64, pcm.Frames())
+	for i := range mono {
+		mono[i] = pcm.Samples[i*2]
+	}
+	pcm.Samples = mono
+	pcm.Channels = 1
+}
+
+// TrimSilence removes leading and trailing frames quieter than thresholdDB
+// padding worth of audio is kept on each side so words are not clipped
+func TrimSilence(pcm *PCM, thresholdDB float64, padding time.Duration) {
+	frames := pcm.Frames()
+	window := pcm.SampleRate / 100 // 10ms analysis windows
+	if frames == 0 || window == 0 {
+		return
+	}
+
+	threshold := math.Pow(10, thresholdDB/20)
+	loud := func(start int) bool {
+		end := min(start+window, frames)
+		var sum float64
+		for i := start * pcm.Channels; i < end*pcm.Channels; i++ {
+			sum += pcm.Samples[i] * pcm.Samples[i]
+		}
+		return math.Sqrt(sum/float64((end-start)*pcm.Channels)) >= threshold
+	}
+
+	first := -1
+	for start := 0; start < frames; start += window {
+		if loud(start) {
+			first = start
+			break
+		}
+	}
+	if first < 0 {
+		// Entirely silent, leave it alone rather than produce an empty file
+		return
+	}
+
+	last := first
+	for start := (frames - 1) / window * window; start > first; start -= window {
+		if loud(start) {
+			last = start
+			break
+		}
+	}
+
+	pad := int(padding.Seconds() * float64(pcm.SampleRate))
+	from := max(first-pad, 0)
+	to := min(last+window+pad, frames)
+
+	pcm.Samples = pcm.Samples[from*pcm.Channels : to*pcm.Channels]
+}
+
+// Normalize applies a gain so the integrated loudness reaches targetLUFS
+// The gain is reduced if it would push the sample peak above peakCeilingDB
+func Normalize(pcm *PCM, targetLUFS, peakCeilingDB float64) {
+	loudness := IntegratedLoudness(pcm)
+	if math.IsInf(loudness, -1) {
+		return
+	}
+
+	gain := math.Pow(10, (targetLUFS-loudness)/20)
+
+	var peak float64
+	for _, s := range pcm.Samples {
+		peak = math.Max(peak, math.Abs(s))
+	}
+	if ceiling := math.Pow(10, peakCeilingDB/20); peak*gain > ceiling {
+		gain = ceiling / peak
+	}
+
+	for i := range pcm.Samples {
+		pcm.Samples[i] *= gain
+	}
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/duolingocards-backend/internal/models"
+	"github.com/example/duolingocards-backend/internal/services/llm"
+	"github.com/example/duolingocards-backend/internal/services/locale"
+)
+
+const (
+	defaultDraftCards = 20
+	maxDraftCards     = 100
+
+	// Priorities run from 1 (nice to know) to 5 (essential), like the bundled decks
+	minPriority     = 1
+	maxPriority     = 5
+	defaultPriority = 3
+)
+
+const draftSystemPrompt = `You write vocabulary flashcards for a language learning app.
+Reply with a single JSON object and nothing else.`
+
+// draftResponse is the JSON object the model is asked to return
+type draftResponse struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Cards       []models.CardInput `json:"cards"`
+}
+
+// DraftDeck asks the language model for a deck on a topic
+// The draft is not stored, editors review it before it is passed to CreateDeck
+func (g *Generator) DraftDeck(ctx context.Context, req models.DeckDraftRequest) (*models.DeckDraft, error) {
+	if g.llm == nil {
+		return nil, fmt.Errorf("language model not configured")
+	}
+
+	req.Topic = strings.TrimSpace(req.Topic)
+	if err := locale.Validate(req.FrontLanguage); err != nil {
+		return nil, fmt.Errorf("frontLanguage: %w", err)
+	}
+	if err := locale.Validate(req.BackLanguage); err != nil {
+		return nil, fmt.Errorf("backLanguage: %w", err)
+	}
+	if req.Topic == "" {
+		return nil, fmt.Errorf("topic required")
+	}
+	if req.CardCount == 0 {
+		req.CardCount = defaultDraftCards
+	}
+	if req.CardCount < 1 || req.CardCount > maxDraftCards {
+		return nil, fmt.Errorf("cardCount must be between 1 and %d", maxDraftCards)
+	}
+
+	text, err := g.llm.Complete(ctx, llm.Request{
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: draftSystemPrompt},
+			{Role: llm.RoleUser, Content: draftPrompt(req)},
+		},
+		Temperature: 0.7,
+		JSON:        true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("draft request failed: %w", err)
+	}
+
+	var resp draftResponse
+	if err := json.Unmarshal([]byte(llm.StripCodeFence(text)), &resp); err != nil {
+		return nil, fmt.Errorf("model returned invalid JSON: %w", err)
+	}
+
+	cards, warnings := cleanDraftCards(resp.Cards, req.CardCount)
+	if len(cards) == 0 {
+		return nil, fmt.Errorf("model returned no usable cards")
+	}
+
+	deck := models.Deck{
+		ID:            g.draftDeckID(req),
+		Name:          strings.TrimSpace(resp.Name),
+		Description:   strings.TrimSpace(resp.Description),
+		FrontLanguage: req.FrontLanguage,
+		BackLanguage:  req.BackLanguage,
+		Level:         req.Level,
+		Cards:         make([]models.Card, 0, len(cards)),
+	}
+	if deck.Name == "" {
+		deck.Name = req.Topic
+	}
+	for _, input := range cards {
+		deck.Cards = append(deck.Cards, models.Card{
+			ID:          input.ID,
+			FrontText:   input.FrontText,
+			BackText:    input.BackText,
+			Reading:     input.Reading,
+			Priority:    input.Priority,
+			MediaStatus: "pending",
+		})
+	}
+
+	return &models.DeckDraft{
+		Deck:     deck,
+		Cards:    cards,
+		Model:    g.llm.Model(),
+		Warnings: warnings,
+	}, nil
+}
+
+func draftPrompt(req models.DeckDraftRequest) string {
+	level := req.Level
+	if level == "" {
+		level = "beginner"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Create %d flashcards for learners of the language %q whose own language is %q.\n", req.CardCount, req.FrontLanguage, req.BackLanguage)
+	fmt.Fprintf(&b, "Topic: %s\nLevel: %s\n\n", req.Topic, level)
+	b.WriteString("Return this JSON object:\n")
+	b.WriteString(`{"name": "deck name", "description": "one sentence", "cards": [{"frontText": "...", "backText": "...", "reading": "...", "priority": 5}]}` + "\n\n")
+	fmt.Fprintf(&b, "- frontText: the word or short phrase in %q, as a native speaker writes it\n", req.FrontLanguage)
+	fmt.Fprintf(&b, "- backText: its translation in %q\n", req.BackLanguage)
+	b.WriteString("- reading: the romanized pronunciation when frontText is not written in Latin script, otherwise an empty string\n")
+	b.WriteString("- priority: 5 for the most essential words down to 1 for the least\n")
+	fmt.Fprintf(&b, "- name and description are written in %q\n", req.BackLanguage)
+	b.WriteString("- no duplicate words, no explanations outside the JSON")
+	return b.String()
+}
+
+// cleanDraftCards drops empty and duplicate entries, clamps priorities and numbers the cards
+func cleanDraftCards(inputs []models.CardInput, limit int) ([]models.CardInput, []string) {
+	var cards []models.CardInput
+	var warnings []string
+	seen := make(map[string]bool)
+
+	for i, input := range inputs {
+		input.FrontText = strings.TrimSpace(input.FrontText)
+		input.BackText = strings.TrimSpace(input.BackText)
+		input.Reading = strings.TrimSpace(input.Reading)
+
+		if input.FrontText == "" || input.BackText == "" {
+			warnings = append(warnings, fmt.Sprintf("entry %d dropped: missing frontText or backText", i+1))
+			continue
+		}
+		if seen[input.FrontText] {
+			warnings = append(warnings, fmt.Sprintf("entry %d dropped: duplicate of %q", i+1, input.FrontText))
+			continue
+		}
+		if len(cards) == limit {
+			warnings = append(warnings, fmt.Sprintf("%d extra entries dropped", len(inputs)-i))
+			break
+		}
+		seen[input.FrontText] = true
+
+		switch {
+		case input.Priority == 0:
+			input.Priority = defaultPriority
+		case input.Priority < minPriority || input.Priority > maxPriority:
+			warnings = append(warnings, fmt.Sprintf("entry %d: priority %d clamped", i+1, input.Priority))
+			input.Priority = min(max(input.Priority, minPriority), maxPriority)
+		}
+
+		input.ID = strconv.Itoa(len(cards) + 1)
+		cards = append(cards, input)
+	}
+
+	if len(cards) > 0 && len(cards) < limit {
+		warnings = append(warnings, fmt.Sprintf("only %d of %d requested cards drafted", len(cards), limit))
+	}
+	return cards, warnings
+}
+
+// draftDeckID derives an unused deck ID from the languages and topic
+func (g *Generator) draftDeckID(req models.DeckDraftRequest) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(req.FrontLanguage + " " + req.Topic) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			slug.WriteRune(r)
+			dash = false
+		} else if !dash && slug.Len() > 0 {
+			slug.WriteByte('-')
+			dash = true
+		}
+	}
+	base := strings.TrimSuffix(slug.String(), "-")
+	if len(base) > 48 {
+		base = strings.TrimSuffix(base[:48], "-")
+	}
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	id := base
+	for n := 2; g.decks[id] != nil; n++ {
+		id = base + "-" + strconv.Itoa(n)
+	}
+	return id
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/example/duolingocards-backend/internal/models"
+	"github.com/example/duolingocards-backend/internal/services/usage"
+)
+
+// ErrBudgetExceeded is returned when a generation would exceed a configured budget
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// ErrGenerationRunning is returned when a deck already has a generation job
+var ErrGenerationRunning = errors.New("generation already running for deck")
+
+// EstimateGeneration returns what a generation request would cost without running it
+func (g *Generator) EstimateGeneration(req models.GenerateRequest) (*models.CostEstimate, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	deck, ok := g.decks[req.DeckID]
+	if !ok {
+		return nil, fmt.Errorf("deck not found: %s", req.DeckID)
+	}
+
+	plan, err := newGenerationPlan(deck, req)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := g.estimatePlan(deck, plan)
+	if err := g.checkBudget(estimate.TotalCost, 0); err != nil {
+		estimate.BudgetError = err.Error()
+	} else {
+		estimate.WithinBudget = true
+	}
+	return estimate, nil
+}
+
+// estimatePlan counts the provider requests generateMedia would make, g.mu must be held
+// Skips assets whose provider is not configured, like the generation run does
+func (g *Generator) estimatePlan(deck *models.Deck, plan *generationPlan) *models.CostEstimate {
+	estimate := &models.CostEstimate{
+		DeckID:        deck.ID,
+		Cards:         len(plan.cards),
+		Currency:      "USD",
+		JobBudget:     g.cfg.JobBudget,
+		MonthlyBudget: g.cfg.MonthlyBudget,
+		MonthToDate:   g.usage.MonthToDate(),
+	}
+
+	addSpeech := func(text string) {
+		estimate.TTSCharacters += int64(utf8.RuneCountInString(text))
+		estimate.TTSRequests++
+	}
+
+	for _, card := range plan.cards {
+		media := card.Media
+		if media == nil {
+			media = &models.Media{}
+		}
+
+		if g.ttsClient != nil && plan.assets[models.AssetAudioFront] {
+			text := speechText(deck, card)
+			if g.needs(plan, deck, card, media.AudioFront) {
+				addSpeech(text)
+			}
+			for _, variant := range deck.AudioVariants {
+				// Generation skips invalid names, decks loaded from storage are not validated
+				if validateVariantName(variant.Name) == nil && g.needs(plan, deck, card, media.AudioVariants[variant.Name]) {
+					addSpeech(text)
+				}
+			}
+		}
+
+		if g.ttsClient != nil && plan.assets[models.AssetAudioBack] && g.needs(plan, deck, card, media.AudioBack) {
+			addSpeech(card.BackText)
+		}
+
+		if g.ttsClient != nil && plan.assets[models.AssetExamples] {
+			// Drafted examples need approval first, only approved ones are synthesized
+			for i := range card.Examples {
+				example := &card.Examples[i]
+				if example.Approved && g.needs(plan, deck, card, example.AudioURL) {
+					addSpeech(exampleSpeechText(deck, example))
+				}
+			}
+		}
+
+		if g.imageClient != nil && plan.assets[models.AssetImage] && g.needs(plan, deck, card, media.Image) {
+			estimate.Images += max(plan.candidateCount(deck), 1)
+		}
+	}
+
+	pricing := g.usage.Pricing()
+	estimate.TTSCost = usage.Round(pricing.Cost(usage.ProviderElevenLabs, estimate.TTSCharacters))
+	estimate.ImageCost = usage.Round(pricing.Cost(usage.ProviderImagen, int64(estimate.Images)))
+	estimate.TotalCost = usage.Round(estimate.TTSCost + estimate.ImageCost)
+	return estimate
+}
+
+// checkBudget reports whether spending cost more fits the job and monthly budgets
+// jobSpent is what the current job has already spent
+func (g *Generator) checkBudget(cost, jobSpent float64) error {
+	if budget := g.cfg.JobBudget; budget > 0 && jobSpent+cost > budget {
+		return fmt.Errorf("%w: job cost %.2f USD, job budget is %.2f USD", ErrBudgetExceeded, jobSpent+cost, budget)
+	}
+
+	if budget := g.cfg.MonthlyBudget; budget > 0 {
+		spent := g.usage.MonthToDate()
+		if spent+cost > budget {
+			return fmt.Errorf("%w: %.2f USD spent this month, %.2f USD more would exceed the monthly budget of %.2f USD", ErrBudgetExceeded, spent, cost, budget)
+		}
+	}
+
+	return nil
+}
+
+// recordUsage accounts a successful provider request to the deck and its running job
+func (g *Generator) recordUsage(deckID, provider string, units int64) {
+	cost := g.usage.Record(deckID, provider, units)
+	if job, ok := g.jobs.Load(deckID); ok {
+		job.(*generationJob).addCost(cost)
+	}
+}
+
+// recordSpeech accounts a synthesized text to ElevenLabs, which bills per character
+func (g *Generator) recordSpeech(deckID, text string) {
+	g.recordUsage(deckID, usage.ProviderElevenLabs, int64(utf8.RuneCountInString(text)))
+}
+
+// UsageReport returns recorded provider usage matching filter
+func (g *Generator) UsageReport(filter usage.Filter) *usage.Report {
+	return g.usage.Report(filter)
+}
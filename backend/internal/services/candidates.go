@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/example/duolingocards-backend/internal/models"
+	"github.com/example/duolingocards-backend/internal/services/events"
+	"github.com/example/duolingocards-backend/internal/services/imageproc"
+	"github.com/example/duolingocards-backend/internal/services/usage"
+)
+
+const (
+	// Card media status while image candidates wait for an editor
+	mediaStatusReview = "review"
+
+	// Candidates generated on rejection when the deck has candidate mode off
+	defaultCandidateCount = 3
+)
+
+// findCard returns the card with cardID or nil
+func findCard(deck *models.Deck, cardID string) *models.Card {
+	for i := range deck.Cards {
+		if deck.Cards[i].ID == cardID {
+			return &deck.Cards[i]
+		}
+	}
+	return nil
+}
+
+// lookupCard returns a deck and one of its cards, g.mu must be held
+func (g *Generator) lookupCard(deckID, cardID string) (*models.Deck, *models.Card, error) {
+	deck, ok := g.decks[deckID]
+	if !ok {
+		return nil, nil, fmt.Errorf("deck not found: %s", deckID)
+	}
+
+	card := findCard(deck, cardID)
+	if card == nil {
+		return nil, nil, fmt.Errorf("card not found: %s", cardID)
+	}
+
+	return deck, card, nil
+}
+
+// generateCandidates requests count images for a card and stores them for review
+func (g *Generator) generateCandidates(ctx context.Context, deck *models.Deck, card *models.Card, count int) error {
+	text, opts, err := g.cardImageRequest(deck, card)
+	if err != nil {
+		return err
+	}
+
+	images, err := g.imageClient.GenerateImages(ctx, text, opts, count)
+	if err != nil {
+		return err
+	}
+	g.recordUsage(deck.ID, usage.ProviderImagen, int64(len(images)))
+
+	now := time.Now()
+	var candidates []models.ImageCandidate
+	for i, data := range images {
+		id := strconv.FormatInt(now.UnixNano(), 36) + "-" + strconv.Itoa(i+1)
+		url, err := g.storage.Save(deck.ID, card.ID, candidateFilename(id), data)
+		if err != nil {
+			continue
+		}
+
+		candidates = append(candidates, models.ImageCandidate{
+			ID:        id,
+			URL:       url,
+			Prompt:    text,
+			CreatedAt: now,
+		})
+	}
+
+	if len(candidates) == 0 {
+		return fmt.Errorf("no candidates stored")
+	}
+
+	g.mu.Lock()
+	card.ImageCandidates = candidates
+	card.MediaStatus = mediaStatusReview
+	g.mu.Unlock()
+
+	for _, candidate := range candidates {
+		g.events.Publish(events.Event{
+			Type:   events.CardImageDone,
+			DeckID: deck.ID,
+			CardID: card.ID,
+			Asset:  "candidate",
+			URL:    candidate.URL,
+			Status: mediaStatusReview,
+		})
+	}
+
+	return nil
+}
+
+func candidateFilename(id string) string {
+	return "candidate-" + id + ".png"
+}
+
+// clearCandidates removes candidate files and entries from a card, g.mu must be held
+func (g *Generator) clearCandidates(deck *models.Deck, card *models.Card) {
+	for _, candidate := range card.ImageCandidates {
+		if err := g.storage.DeleteFile(deck.ID, card.ID, path.Base(candidate.URL)); err != nil {
+			log.Printf("Failed to delete candidate %s for %s/%s: %v", candidate.ID, deck.ID, card.ID, err)
+		}
+	}
+	card.ImageCandidates = nil
+	if card.MediaStatus == mediaStatusReview {
+		card.MediaStatus = "ready"
+	}
+}
+
+// ListCandidates returns the cards of a deck that have image candidates pending review
+func (g *Generator) ListCandidates(deckID string) ([]models.CandidateReview, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	deck, ok := g.decks[deckID]
+	if !ok {
+		return nil, fmt.Errorf("deck not found: %s", deckID)
+	}
+
+	reviews := []models.CandidateReview{}
+	for _, card := range deck.Cards {
+		if len(card.ImageCandidates) == 0 {
+			continue
+		}
+
+		review := models.CandidateReview{
+			CardID:     card.ID,
+			FrontText:  card.FrontText,
+			BackText:   card.BackText,
+			Candidates: card.ImageCandidates,
+		}
+		if card.Media != nil {
+			review.Image = card.Media.Image
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, nil
+}
+
+// SelectCandidate makes a candidate the card image and discards the others
+func (g *Generator) SelectCandidate(deckID, cardID, candidateID string) (*models.Card, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	deck, card, err := g.lookupCard(deckID, cardID)
+	if err != nil {
+		return nil, err
+	}
+	if _, running := g.jobs.Load(deck.ID); running {
+		return nil, fmt.Errorf("%w: %s", ErrGenerationRunning, deck.ID)
+	}
+
+	var selected *models.ImageCandidate
+	for i := range card.ImageCandidates {
+		if card.ImageCandidates[i].ID == candidateID {
+			selected = &card.ImageCandidates[i]
+			break
+		}
+	}
+	if selected == nil {
+		return nil, fmt.Errorf("candidate not found: %s", candidateID)
+	}
+
+	data, err := g.storage.Load(deck.ID, card.ID, path.Base(selected.URL))
+	if err != nil {
+		return nil, err
+	}
+
+	g.saveImage(deck, card, data)
+	g.clearCandidates(deck, card)
+
+	if err := g.saveDeck(deck); err != nil {
+		return nil, err
+	}
+	g.deckUpdated(deck)
+	return card, nil
+}
+
+// RejectCandidates discards all candidates of a card and starts a job generating a new set
+func (g *Generator) RejectCandidates(deckID, cardID string) (*models.GenerateStatus, error) {
+	if g.imageClient == nil {
+		return nil, fmt.Errorf("image provider not configured")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	deck, card, err := g.lookupCard(deckID, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, running := g.jobs.Load(deck.ID); running {
+		return nil, fmt.Errorf("%w: %s", ErrGenerationRunning, deck.ID)
+	}
+
+	plan := &generationPlan{
+		cards:      []*models.Card{card},
+		assets:     map[string]bool{models.AssetImage: true},
+		candidates: deck.ImageCandidates,
+	}
+	if plan.candidates < 2 {
+		plan.candidates = defaultCandidateCount
+	}
+
+	if err := g.checkBudget(g.estimatePlan(deck, plan).TotalCost, 0); err != nil {
+		return nil, err
+	}
+
+	g.clearCandidates(deck, card)
+	card.MediaStatus = "generating"
+
+	return g.startJob(deck, plan), nil
+}
+
+// ReplaceImage stores an uploaded image for a card and discards pending candidates
+func (g *Generator) ReplaceImage(deckID, cardID string, data []byte) (*models.Card, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	deck, card, err := g.lookupCard(deckID, cardID)
+	if err != nil {
+		return nil, err
+	}
+	if _, running := g.jobs.Load(deck.ID); running {
+		return nil, fmt.Errorf("%w: %s", ErrGenerationRunning, deck.ID)
+	}
+
+	if _, err := imageproc.Analyze(data); err != nil {
+		return nil, fmt.Errorf("invalid image: %w", err)
+	}
+
+	g.saveImage(deck, card, data)
+	g.clearCandidates(deck, card)
+	card.MediaStatus = "ready"
+
+	if err := g.saveDeck(deck); err != nil {
+		return nil, err
+	}
+	g.deckUpdated(deck)
+	return card, nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/example/duolingocards-backend/internal/models"
+	"github.com/example/duolingocards-backend/internal/services/iap"
+	"github.com/example/duolingocards-backend/internal/services/locale"
+	"github.com/example/duolingocards-backend/internal/services/search"
+)
+
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 200
+	maxQueryLength     = 100
+)
+
+// SearchAccess decides which paid decks a search shows in full
+type SearchAccess struct {
+	Editor bool // Editors see every deck in full
+
+	// Owned returns which of the given paid decks the caller bought, nil for anonymous searches
+	Owned func(deckIDs []string) map[string]bool
+}
+
+// unlocked returns the paid decks among hits that the caller may see in full
+func (a SearchAccess) unlocked(hits []search.Hit, freeDecks []string) map[string]bool {
+	if a.Owned == nil {
+		return nil
+	}
+
+	var paid []string
+	for _, hit := range hits {
+		if iap.IsPaidDeck(hit.DeckID, freeDecks) && !slices.Contains(paid, hit.DeckID) {
+			paid = append(paid, hit.DeckID)
+		}
+	}
+	if len(paid) == 0 {
+		return nil
+	}
+	return a.Owned(paid)
+}
+
+// indexDeck replaces the deck's cards in the search index
+func (g *Generator) indexDeck(deck *models.Deck) {
+	docs := make([]search.Document, 0, len(deck.Cards))
+	for _, card := range deck.Cards {
+		back := []string{card.BackText}
+		for _, text := range card.Translations {
+			back = append(back, text)
+		}
+
+		docs = append(docs, search.Document{
+			CardID: card.ID,
+			Fields: map[string][]string{
+				search.FieldFront:   {card.FrontText},
+				search.FieldBack:    back,
+				search.FieldReading: {card.Reading},
+			},
+		})
+	}
+	g.search.Update(deck.ID, docs)
+}
+
+// Search finds cards across decks by front text, back text in any language
+// and reading, ignoring case, diacritics and kana width
+// Paid decks the caller has not bought only show hits on the front text and
+// nothing else of the card; editors see every deck in full
+func (g *Generator) Search(query string, languages []string, limit int, access SearchAccess) (*models.SearchResults, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("q required")
+	}
+	if utf8.RuneCountInString(query) > maxQueryLength {
+		return nil, fmt.Errorf("q must be at most %d characters", maxQueryLength)
+	}
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if limit < 1 || limit > maxSearchLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxSearchLimit)
+	}
+
+	hits := g.search.Search(query)
+
+	// Receipts are checked before taking the lock, they may call the store
+	var owned map[string]bool
+	if !access.Editor {
+		owned = access.unlocked(hits, g.cfg.FreeDecks)
+	}
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	results := &models.SearchResults{Query: query, Decks: []models.DeckHits{}}
+	groups := make(map[string]int) // deck ID -> index in results.Decks
+
+	for _, hit := range hits {
+		deck, ok := g.decks[hit.DeckID]
+		if !ok {
+			continue
+		}
+		card := findCard(deck, hit.CardID)
+		if card == nil {
+			continue
+		}
+
+		locked := !access.Editor && !owned[deck.ID] && iap.IsPaidDeck(deck.ID, g.cfg.FreeDecks)
+		if locked && !slices.Contains(hit.Matched, search.FieldFront) {
+			continue
+		}
+
+		results.Total++
+		if results.Total > limit {
+			continue
+		}
+
+		i, ok := groups[deck.ID]
+		if !ok {
+			lang, ok := locale.Match(languages, backLanguages(deck))
+			if !ok {
+				lang = deck.BackLanguage
+			}
+			i = len(results.Decks)
+			groups[deck.ID] = i
+			results.Decks = append(results.Decks, models.DeckHits{
+				DeckID:        deck.ID,
+				Name:          deckMetadata(deck, languages).Name,
+				FrontLanguage: deck.FrontLanguage,
+				BackLanguage:  lang,
+				Locked:        locked,
+			})
+		}
+		group := &results.Decks[i]
+
+		result := models.SearchHit{
+			CardID:    card.ID,
+			FrontText: card.FrontText,
+			Matched:   hit.Matched,
+		}
+		if locked {
+			result.Matched = []string{search.FieldFront}
+		} else {
+			localized := localizeCard(*card, deck.BackLanguage, group.BackLanguage)
+			result.BackText = localized.BackText
+			result.Reading = card.Reading
+		}
+		group.Hits = append(group.Hits, result)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/example/duolingocards-backend/internal/models"
+	"github.com/example/duolingocards-backend/internal/services/iap"
+	"github.com/example/duolingocards-backend/internal/services/locale"
+	"github.com/example/duolingocards-backend/internal/services/search"
+)
+
+const (
+	defaultCatalogLimit = 20
+	maxCatalogLimit     = 100
+)
+
+// Catalog sort orders, ties are broken by deck ID so pages are stable
+const (
+	SortPopularity = "popularity" // Most downloaded first
+	SortNewest     = "newest"
+	SortName       = "name" // By name in the requested locale
+)
+
+// Catalog price filters
+const (
+	PriceFree = "free"
+	PricePaid = "paid"
+)
+
+// CatalogQuery filters, sorts and pages the catalog, empty fields match everything
+type CatalogQuery struct {
+	FrontLanguage string   // Language being learned
+	BackLanguage  string   // Matches any language the cards can be shown in
+	Price         string   // PriceFree or PricePaid
+	Level         string   // e.g. "A1" or "beginner"
+	Tags          []string // Decks must have every tag
+	Search        string   // Words matched against names, descriptions and tags in every locale
+	Sort          string   // Defaults to SortPopularity
+	Cursor        string   // NextCursor of the previous page
+	Limit         int      // Defaults to 20, at most 100
+	Languages     []string // Preferred locales for names and descriptions
+}
+
+// catalogKey is the position of a deck in a sort order
+type catalogKey struct {
+	Downloads int    `json:"downloads,omitempty"`
+	Created   int64  `json:"created,omitempty"`
+	Name      string `json:"name,omitempty"`
+	ID        string `json:"id"`
+}
+
+// catalogCursor is encoded into NextCursor, the page after it starts past Key
+type catalogCursor struct {
+	Sort string     `json:"sort"`
+	Key  catalogKey `json:"key"`
+}
+
+func compareCatalogKeys(order string, a, b catalogKey) int {
+	var c int
+	switch order {
+	case SortPopularity:
+		c = cmp.Compare(b.Downloads, a.Downloads)
+	case SortNewest:
+		c = cmp.Compare(b.Created, a.Created)
+	case SortName:
+		c = cmp.Compare(a.Name, b.Name)
+	}
+	if c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// validate fills defaults and checks the query
+func (q *CatalogQuery) validate() error {
+	q.Sort = cmp.Or(q.Sort, SortPopularity)
+	switch q.Sort {
+	case SortPopularity, SortNewest, SortName:
+	default:
+		return fmt.Errorf("sort must be %s, %s or %s", SortPopularity, SortNewest, SortName)
+	}
+
+	switch q.Price {
+	case "", PriceFree, PricePaid:
+	default:
+		return fmt.Errorf("price must be %s or %s", PriceFree, PricePaid)
+	}
+
+	for _, tag := range []string{q.FrontLanguage, q.BackLanguage} {
+		if tag != "" && !locale.WellFormed(tag) {
+			return fmt.Errorf("invalid language tag %q", tag)
+		}
+	}
+
+	if q.Limit == 0 {
+		q.Limit = defaultCatalogLimit
+	}
+	if q.Limit < 1 || q.Limit > maxCatalogLimit {
+		return fmt.Errorf("limit must be between 1 and %d", maxCatalogLimit)
+	}
+	return nil
+}
+
+// GetCatalog returns one page of decks matching q with store metadata in the
+// best match for q.Languages, and the number of matching decks
+func (g *Generator) GetCatalog(q CatalogQuery) (*models.Catalog, error) {
+	if err := q.validate(); err != nil {
+		return nil, err
+	}
+
+	var after *catalogKey
+	if q.Cursor != "" {
+		cursor, err := decodeCatalogCursor(q.Cursor)
+		if err != nil || cursor.Sort != q.Sort {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+		after = &cursor.Key
+	}
+
+	type entry struct {
+		item models.CatalogItem
+		key  catalogKey
+	}
+
+	g.mu.RLock()
+	var entries []entry
+	for _, deck := range g.decks {
+		item := g.catalogItem(deck, q.Languages)
+		if !q.matches(deck, item) {
+			continue
+		}
+		entries = append(entries, entry{
+			item: item,
+			key: catalogKey{
+				Downloads: g.downloads.Count(deck.ID),
+				Created:   deck.CreatedAt.UnixNano(),
+				Name:      strings.ToLower(item.Name),
+				ID:        deck.ID,
+			},
+		})
+	}
+	g.mu.RUnlock()
+
+	slices.SortFunc(entries, func(a, b entry) int {
+		return compareCatalogKeys(q.Sort, a.key, b.key)
+	})
+
+	start := 0
+	if after != nil {
+		start, _ = slices.BinarySearchFunc(entries, *after, func(e entry, key catalogKey) int {
+			// Entries equal to the cursor were on the previous page
+			if c := compareCatalogKeys(q.Sort, e.key, key); c != 0 {
+				return c
+			}
+			return -1
+		})
+	}
+	end := min(start+q.Limit, len(entries))
+
+	catalog := &models.Catalog{
+		Decks: []models.CatalogItem{},
+		Total: len(entries),
+	}
+	for _, e := range entries[start:end] {
+		catalog.Decks = append(catalog.Decks, e.item)
+	}
+	if end < len(entries) {
+		catalog.NextCursor = encodeCatalogCursor(catalogCursor{Sort: q.Sort, Key: entries[end-1].key})
+	}
+
+	return catalog, nil
+}
+
+// catalogItem builds the store entry for a deck
+func (g *Generator) catalogItem(deck *models.Deck, languages []string) models.CatalogItem {
+	meta := deckMetadata(deck, languages)
+	item := models.CatalogItem{
+		ID:           deck.ID,
+		Name:         meta.Name,
+		Description:  meta.Description,
+		ThumbnailURL: meta.ThumbnailURL,
+		CardCount:    len(deck.Cards),
+		Languages:    []string{deck.FrontLanguage, deck.BackLanguage},
+		Level:        deck.Level,
+		Tags:         deck.Tags,
+		CreatedAt:    deck.CreatedAt,
+
+		BackLanguages: backLanguages(deck),
+	}
+
+	if iap.IsPaidDeck(deck.ID, g.cfg.FreeDecks) {
+		item.Price = "tier1"
+		item.IAPProductID = iap.DeckProductID(deck.ID)
+	} else {
+		item.Price = "free"
+	}
+
+	return item
+}
+
+func (q *CatalogQuery) matches(deck *models.Deck, item models.CatalogItem) bool {
+	if q.FrontLanguage != "" {
+		if _, ok := locale.Match([]string{q.FrontLanguage}, []string{deck.FrontLanguage}); !ok {
+			return false
+		}
+	}
+	if q.BackLanguage != "" {
+		if _, ok := locale.Match([]string{q.BackLanguage}, item.BackLanguages); !ok {
+			return false
+		}
+	}
+
+	switch q.Price {
+	case PriceFree:
+		if item.Price != "free" {
+			return false
+		}
+	case PricePaid:
+		if item.Price == "free" {
+			return false
+		}
+	}
+
+	if q.Level != "" && !strings.EqualFold(q.Level, deck.Level) {
+		return false
+	}
+
+	for _, tag := range q.Tags {
+		if !slices.ContainsFunc(deck.Tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			return false
+		}
+	}
+
+	if words := strings.Fields(search.Normalize(q.Search)); len(words) > 0 {
+		text := searchableText(deck)
+		for _, word := range words {
+			if !strings.Contains(text, word) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// searchableText joins the deck metadata of every locale, normalized like card search
+func searchableText(deck *models.Deck) string {
+	parts := []string{deck.ID, deck.Name, deck.Description, deck.Level}
+	parts = append(parts, deck.Tags...)
+	for _, localization := range deck.Localizations {
+		parts = append(parts, localization.Name, localization.Description)
+	}
+	return search.Normalize(strings.Join(parts, "\n"))
+}
+
+func encodeCatalogCursor(cursor catalogCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCatalogCursor(s string) (catalogCursor, error) {
+	var cursor catalogCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(data, &cursor)
+	return cursor, err
+}
+
+// RecordDownload counts a deck download for popularity sorting
+func (g *Generator) RecordDownload(deckID string) {
+	g.downloads.Record(deckID)
+}
+
+// DownloadStats counts deck downloads, persisted as one JSON file
+type DownloadStats struct {
+	path   string
+	counts map[string]int
+	mu     sync.RWMutex
+}
+
+// NewDownloadStats keeps download counts in downloads.json under dir
+func NewDownloadStats(dir string) *DownloadStats {
+	s := &DownloadStats{
+		path:   filepath.Join(dir, "downloads.json"),
+		counts: make(map[string]int),
+	}
+	if data, err := os.ReadFile(s.path); err == nil {
+		json.Unmarshal(data, &s.counts)
+	}
+	return s
+}
+
+func (s *DownloadStats) Count(deckID string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.counts[deckID]
+}
+
+func (s *DownloadStats) Record(deckID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.counts[deckID]++
+	os.MkdirAll(filepath.Dir(s.path), 0755)
+	if data, err := json.MarshalIndent(s.counts, "", "  "); err == nil {
+		os.WriteFile(s.path, data, 0644)
+	}
+}
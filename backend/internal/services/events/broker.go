@@ -0,0 +1,97 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types published during generation
+const (
+	JobStarted   = "job.started"
+	JobPaused    = "job.paused"
+	JobResumed   = "job.resumed"
+	JobCancelled = "job.cancelled"
+	JobCompleted = "job.completed"
+	JobStatus    = "job.status" // Snapshot sent to new subscribers
+
+	CardStarted   = "card.started"
+	CardAudioDone = "card.audio_done"
+	CardImageDone = "card.image_done"
+	CardFailed    = "card.failed"
+	CardCompleted = "card.completed"
+)
+
+// subscriberBuffer is how many events a slow subscriber may lag behind before events are dropped
+const subscriberBuffer = 64
+
+// Event is a single progress notification for a deck
+type Event struct {
+	Type     string    `json:"type"`
+	DeckID   string    `json:"deckId"`
+	CardID   string    `json:"cardId,omitempty"`
+	Asset    string    `json:"asset,omitempty"`   // audioFront, audioBack, image, candidate
+	Variant  string    `json:"variant,omitempty"` // Audio variant name, e.g. "slow"
+	URL      string    `json:"url,omitempty"`
+	Status   string    `json:"status,omitempty"`
+	Progress int       `json:"progress,omitempty"`
+	Total    int       `json:"total,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// Broker is an in-process pub/sub for generation events keyed by deck ID
+type Broker struct {
+	subscribers map[string]map[chan Event]struct{}
+	mu          sync.RWMutex
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[string]map[chan Event]struct{}),
+	}
+}
+
+// Subscribe returns a channel receiving events for deckID and a function to unsubscribe
+func (b *Broker) Subscribe(deckID string) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[deckID] == nil {
+		b.subscribers[deckID] = make(map[chan Event]struct{})
+	}
+	b.subscribers[deckID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers[deckID], ch)
+			if len(b.subscribers[deckID]) == 0 {
+				delete(b.subscribers, deckID)
+			}
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+// Publish delivers an event to all subscribers of its deck without blocking
+// Subscribers whose buffer is full miss the event
+func (b *Broker) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers[event.DeckID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/example/duolingocards-backend/internal/models"
+	"github.com/example/duolingocards-backend/internal/services/llm"
+)
+
+const (
+	defaultExampleCount = 2
+	maxExampleCount     = 5
+)
+
+// exampleResponse is the JSON object the model is asked to return
+type exampleResponse struct {
+	Examples []models.Example `json:"examples"`
+}
+
+// draftExamples asks the language model for count example sentences using the card word
+func (g *Generator) draftExamples(ctx context.Context, deck *models.Deck, card *models.Card, count int) ([]models.Example, error) {
+	if g.llm == nil {
+		return nil, fmt.Errorf("language model not configured")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Write %d short, natural example sentences in %q using %q (%s).\n", count, deck.FrontLanguage, card.FrontText, card.BackText)
+	b.WriteString("Keep them simple enough for learners who are just meeting this word.\n\n")
+	b.WriteString("Return this JSON object:\n")
+	b.WriteString(`{"examples": [{"text": "...", "reading": "...", "translation": "..."}]}` + "\n\n")
+	fmt.Fprintf(&b, "- text: the sentence in %q\n", deck.FrontLanguage)
+	b.WriteString("- reading: the romanized pronunciation when text is not written in Latin script, otherwise an empty string\n")
+	fmt.Fprintf(&b, "- translation: the sentence in %q", deck.BackLanguage)
+
+	text, err := g.llm.Complete(ctx, llm.Request{
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: draftSystemPrompt},
+			{Role: llm.RoleUser, Content: b.String()},
+		},
+		Temperature: 0.7,
+		JSON:        true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("example request failed: %w", err)
+	}
+
+	var resp exampleResponse
+	if err := json.Unmarshal([]byte(llm.StripCodeFence(text)), &resp); err != nil {
+		return nil, fmt.Errorf("model returned invalid JSON: %w", err)
+	}
+
+	var examples []models.Example
+	for _, example := range resp.Examples {
+		example.Text = strings.TrimSpace(example.Text)
+		example.Reading = strings.TrimSpace(example.Reading)
+		example.Translation = strings.TrimSpace(example.Translation)
+		if example.Text == "" || example.Translation == "" {
+			continue
+		}
+
+		examples = append(examples, models.Example{
+			Text:        example.Text,
+			Reading:     example.Reading,
+			Translation: example.Translation,
+		})
+		if len(examples) == count {
+			break
+		}
+	}
+
+	if len(examples) == 0 {
+		return nil, fmt.Errorf("model returned no usable examples")
+	}
+	return examples, nil
+}
+
+// addExamples replaces the unapproved examples of a card, approved ones are kept
+func addExamples(card *models.Card, examples []models.Example) {
+	next := 1
+	kept := card.Examples[:0]
+	for _, example := range card.Examples {
+		if id, err := strconv.Atoi(example.ID); err == nil && id >= next {
+			next = id + 1
+		}
+		if example.Approved {
+			kept = append(kept, example)
+		}
+	}
+
+	for _, example := range examples {
+		example.ID = strconv.Itoa(next)
+		next++
+		kept = append(kept, example)
+	}
+	card.Examples = kept
+}
+
+// generateExamples drafts examples for a card without any and synthesizes approved ones
+// Used by generation runs that include the examples asset
+func (g *Generator) generateExamples(ctx context.Context, deck *models.Deck, card *models.Card, plan *generationPlan) {
+	if len(card.Examples) == 0 && g.llm != nil {
+		examples, err := g.draftExamples(ctx, deck, card, defaultExampleCount)
+		if err != nil {
+			g.cardFailed(deck, card, models.AssetExamples, "", err)
+			return
+		}
+		addExamples(card, examples)
+	}
+
+	if g.ttsClient == nil {
+		return
+	}
+	voiceID, err := ttsVoice(deck.FrontLanguage, deck.TTSVoiceID)
+	if err != nil {
+		g.cardFailed(deck, card, models.AssetExamples, "", err)
+		return
+	}
+
+	for i := range card.Examples {
+		example := &card.Examples[i]
+		if !example.Approved || !g.needs(plan, deck, card, example.AudioURL) {
+			continue
+		}
+
+		text := exampleSpeechText(deck, example)
+		audioData, err := g.ttsClient.GenerateSpeechWithOptions(ctx, text, voiceID, speechOptions(deck))
+		if err != nil {
+			g.cardFailed(deck, card, models.AssetExamples, example.ID, err)
+			continue
+		}
+		g.recordSpeech(deck.ID, text)
+
+		id := example.ID
+		g.saveAudio(deck, card, "example-"+id, "example-"+id, audioData, func(m *models.Media, url string) {
+			if example := findExample(card, id); example != nil {
+				example.AudioURL = url
+			}
+		})
+	}
+}
+
+// exampleSpeechText mirrors speechText for example sentences
+func exampleSpeechText(deck *models.Deck, example *models.Example) string {
+	if deck.TTSUseReading && example.Reading != "" {
+		return speechReading(deck.FrontLanguage, example.Text, example.Reading)
+	}
+	return example.Text
+}
+
+func findExample(card *models.Card, exampleID string) *models.Example {
+	for i := range card.Examples {
+		if card.Examples[i].ID == exampleID {
+			return &card.Examples[i]
+		}
+	}
+	return nil
+}
+
+// GenerateExamples drafts new examples for a card, replacing those not yet approved
+func (g *Generator) GenerateExamples(ctx context.Context, deckID, cardID string, count int) (*models.Card, error) {
+	if count == 0 {
+		count = defaultExampleCount
+	}
+	if count < 1 || count > maxExampleCount {
+		return nil, fmt.Errorf("count must be between 1 and %d", maxExampleCount)
+	}
+
+	g.mu.RLock()
+	deck, card, err := g.lookupCard(deckID, cardID)
+	g.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	examples, err := g.draftExamples(ctx, deck, card, count)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	addExamples(card, examples)
+	if err := g.saveDeck(deck); err != nil {
+		return nil, err
+	}
+	g.deckUpdated(deck)
+	return card, nil
+}
+
+// UpdateExample edits or approves an example
+// Changing the text drops its audio so the next generation run synthesizes it again
+func (g *Generator) UpdateExample(deckID, cardID, exampleID string, update models.ExampleUpdate) (*models.Card, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	deck, card, err := g.lookupCard(deckID, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	example := findExample(card, exampleID)
+	if example == nil {
+		return nil, fmt.Errorf("example not found: %s", exampleID)
+	}
+
+	if update.Text != nil {
+		text := strings.TrimSpace(*update.Text)
+		if text == "" {
+			return nil, fmt.Errorf("text must not be empty")
+		}
+		if text != example.Text {
+			example.Text = text
+			example.AudioURL = ""
+		}
+	}
+	if update.Reading != nil {
+		if reading := strings.TrimSpace(*update.Reading); reading != example.Reading {
+			example.Reading = reading
+			if deck.TTSUseReading {
+				example.AudioURL = ""
+			}
+		}
+	}
+	if update.Translation != nil {
+		example.Translation = strings.TrimSpace(*update.Translation)
+	}
+	if update.Approved != nil {
+		example.Approved = *update.Approved
+	}
+
+	if err := g.saveDeck(deck); err != nil {
+		return nil, err
+	}
+	g.deckUpdated(deck)
+	return card, nil
+}
+
+// DeleteExample removes an example from a card
+func (g *Generator) DeleteExample(deckID, cardID, exampleID string) (*models.Card, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	deck, card, err := g.lookupCard(deckID, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range card.Examples {
+		if card.Examples[i].ID == exampleID {
+			if url := card.Examples[i].AudioURL; url != "" {
+				if err := g.storage.DeleteFile(deck.ID, card.ID, path.Base(url)); err != nil {
+					log.Printf("Failed to delete example audio for %s/%s: %v", deck.ID, card.ID, err)
+				}
+			}
+			card.Examples = append(card.Examples[:i], card.Examples[i+1:]...)
+			if err := g.saveDeck(deck); err != nil {
+				return nil, err
+			}
+			g.deckUpdated(deck)
+			return card, nil
+		}
+	}
+
+	return nil, fmt.Errorf("example not found: %s", exampleID)
+}
@@ -1,56 +1,171 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/example/duolingocards-backend/internal/config"
 	"github.com/example/duolingocards-backend/internal/models"
+	"github.com/example/duolingocards-backend/internal/services/audio"
+	"github.com/example/duolingocards-backend/internal/services/events"
 	"github.com/example/duolingocards-backend/internal/services/image"
+	"github.com/example/duolingocards-backend/internal/services/imageproc"
+	"github.com/example/duolingocards-backend/internal/services/llm"
+	"github.com/example/duolingocards-backend/internal/services/prompt"
+	"github.com/example/duolingocards-backend/internal/services/ratelimit"
+	"github.com/example/duolingocards-backend/internal/services/recorder"
+	"github.com/example/duolingocards-backend/internal/services/search"
+	"github.com/example/duolingocards-backend/internal/services/translate"
 	"github.com/example/duolingocards-backend/internal/services/tts"
+	"github.com/example/duolingocards-backend/internal/services/usage"
+	"github.com/example/duolingocards-backend/internal/services/webhooks"
 	"github.com/example/duolingocards-backend/internal/storage"
 )
 
 const defaultImagePromptTemplate = "Simple, clean illustration for vocabulary flashcard showing '{word}'. Minimalist, colorful icon-style. No text, no letters. White background."
 
 type Generator struct {
-	ttsClient    *tts.ElevenLabsClient
-	imageClient  *image.ImagenClient
-	storage      *storage.LocalStorage
-	cfg          *config.Config
+	ttsClient      *tts.ElevenLabsClient
+	imageClient    *image.ImagenClient
+	llm            llm.Provider
+	translator     translate.Provider
+	audioProcessor *audio.Processor
+	storage        *storage.LocalStorage
+	styles         *StyleStore
+	events         *events.Broker
+	webhooks       *webhooks.Dispatcher
+	usage          *usage.Tracker
+	downloads      *DownloadStats
+	search         *search.Index
+	cfg            *config.Config
 
 	// In-memory deck storage (replace with DB in production)
 	decks    map[string]*models.Deck
 	statuses map[string]*models.GenerateStatus
+	jobs     sync.Map // deck ID -> *generationJob while generation runs
 	mu       sync.RWMutex
 }
 
 func NewGenerator(cfg *config.Config) *Generator {
 	g := &Generator{
-		cfg:      cfg,
-		decks:    make(map[string]*models.Deck),
-		statuses: make(map[string]*models.GenerateStatus),
-		storage:  storage.NewLocalStorage(cfg.StoragePath, cfg.StorageBaseURL),
+		cfg:       cfg,
+		decks:     make(map[string]*models.Deck),
+		statuses:  make(map[string]*models.GenerateStatus),
+		storage:   storage.NewLocalStorage(cfg.StoragePath, cfg.StorageBaseURL),
+		styles:    NewStyleStore(cfg.StatePath("styles")),
+		downloads: NewDownloadStats(cfg.StatePath("stats")),
+		search:    search.NewIndex(),
+		events:    events.NewBroker(),
+		usage: usage.NewTracker(cfg.StatePath("usage"), usage.Pricing{
+			TTSPerThousandChars: cfg.TTSCostPer1KChars,
+			ImagePrice:          cfg.ImageCost,
+		}),
 	}
 
-	if cfg.ElevenLabsKey != "" {
+	// Replay needs no credentials, recordings stand in for the provider
+	if cfg.ElevenLabsKey != "" || cfg.ElevenLabsHTTPMode == recorder.ModeReplay {
 		g.ttsClient = tts.NewElevenLabsClient(cfg.ElevenLabsKey)
+		g.ttsClient.SetTransport(ProviderTransport(cfg, "elevenlabs", cfg.ElevenLabsHTTPMode, &ratelimit.Transport{
+			Limiter:    ratelimit.New(cfg.ElevenLabsRate, cfg.ElevenLabsBurst, cfg.ElevenLabsMaxConcurrency),
+			MaxRetries: cfg.ProviderMaxRetries,
+		}, recorder.Redaction{}))
+		if cfg.ElevenLabsVoiceID != "" {
+			g.ttsClient.SetVoiceID(cfg.ElevenLabsVoiceID)
+		}
+	}
+
+	if cfg.AudioPostProcess && cfg.AudioEncoder == audio.KeepMP3 {
+		log.Printf("Audio post-processing disabled: AUDIO_ENCODER=%s keeps the provider's MP3, set an encoder to process audio", audio.KeepMP3)
+	} else if cfg.AudioPostProcess {
+		encoder, err := audio.NewEncoder(cfg.AudioEncoder)
+		if err != nil {
+			log.Printf("Audio post-processing disabled: %v", err)
+		} else {
+			g.audioProcessor = audio.NewProcessor(audio.Options{
+				SilenceThresholdDB: cfg.AudioSilenceThresholdDB,
+				SilencePadding:     50 * time.Millisecond,
+				TargetLUFS:         cfg.AudioTargetLUFS,
+				PeakCeilingDB:      -1,
+			}, encoder)
+		}
 	}
 
-	if cfg.GoogleAPIKey != "" {
+	if cfg.GoogleAPIKey != "" || cfg.ImagenHTTPMode == recorder.ModeReplay {
 		g.imageClient = image.NewImagenClient(cfg.GoogleAPIKey)
+		g.imageClient.SetTransport(ProviderTransport(cfg, "imagen", cfg.ImagenHTTPMode, &ratelimit.Transport{
+			Limiter:    ratelimit.New(cfg.ImagenRate, cfg.ImagenBurst, cfg.ImagenMaxConcurrency),
+			MaxRetries: cfg.ProviderMaxRetries,
+		}, recorder.Redaction{QueryParams: []string{"key"}}))
+	}
+
+	if cfg.LLMProvider != "" {
+		provider, err := llm.New(llm.Config{
+			Provider: cfg.LLMProvider,
+			BaseURL:  cfg.LLMBaseURL,
+			APIKey:   cfg.LLMAPIKey,
+			Model:    cfg.LLMModel,
+		})
+		if err != nil {
+			log.Printf("Deck drafting disabled: %v", err)
+		} else {
+			provider.SetTransport(ProviderTransport(cfg, "llm", cfg.LLMHTTPMode, http.DefaultTransport, recorder.Redaction{}))
+			g.llm = provider
+		}
+	}
+
+	if cfg.TranslateProvider != "" {
+		provider, err := translate.New(translate.Config{
+			Provider: cfg.TranslateProvider,
+			BaseURL:  cfg.TranslateBaseURL,
+			APIKey:   cfg.TranslateAPIKey,
+		})
+		if err != nil {
+			log.Printf("Deck translation disabled: %v", err)
+		} else {
+			provider.SetTransport(ProviderTransport(cfg, "translate", cfg.TranslateHTTPMode, http.DefaultTransport,
+				recorder.Redaction{JSONFields: []string{"api_key"}}))
+			g.translator = provider
+		}
 	}
 
+	setupLanguages(cfg)
+	setupTransliteration(cfg)
+
 	// Load existing decks from storage
 	g.loadDecksFromStorage()
 
 	return g
 }
 
+// ProviderTransport wraps base for record/replay according to mode
+// Recordings of each provider live in their own directory under RecordingsPath
+func ProviderTransport(cfg *config.Config, provider, mode string, base http.RoundTripper, redact recorder.Redaction) http.RoundTripper {
+	rt, err := recorder.Wrap(base, mode, filepath.Join(cfg.RecordingsPath, provider), redact)
+	if err != nil {
+		log.Fatalf("%s: %v", provider, err)
+	}
+	if mode == recorder.ModeRecord || mode == recorder.ModeReplay {
+		log.Printf("%s HTTP calls in %s mode (%s)", provider, mode, cfg.RecordingsPath)
+	}
+	return rt
+}
+
+// SetWebhooks registers the dispatcher notified about generation and deck events
+func (g *Generator) SetWebhooks(dispatcher *webhooks.Dispatcher) {
+	g.webhooks = dispatcher
+}
+
 func (g *Generator) loadDecksFromStorage() {
 	decksPath := filepath.Join(g.cfg.StoragePath, "decks")
 	entries, err := os.ReadDir(decksPath)
@@ -71,8 +186,25 @@ func (g *Generator) loadDecksFromStorage() {
 			if err := json.Unmarshal(data, &deck); err != nil {
 				continue
 			}
+			if err := validateLanguages(&deck); err != nil {
+				log.Printf("Deck %s has invalid languages: %v", deck.ID, err)
+			}
+			if deck.CreatedAt.IsZero() {
+				if info, err := entry.Info(); err == nil {
+					deck.CreatedAt = info.ModTime().UTC()
+				}
+			}
+
+			filled := fillReadings(&deck)
+			if filled > 0 {
+				log.Printf("Filled %d missing readings in deck %s", filled, deck.ID)
+			}
+			if annotateFurigana(&deck) > 0 || filled > 0 {
+				g.saveDeck(&deck)
+			}
 
 			g.decks[deck.ID] = &deck
+			g.indexDeck(&deck)
 		}
 	}
 }
@@ -86,46 +218,23 @@ func (g *Generator) saveDeck(deck *models.Deck) error {
 		return err
 	}
 
-	return os.WriteFile(filepath.Join(decksPath, deck.ID+".json"), data, 0644)
-}
-
-func (g *Generator) GetCatalog() *models.Catalog {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-
-	catalog := &models.Catalog{Decks: []models.CatalogItem{}}
-
-	for _, deck := range g.decks {
-		item := models.CatalogItem{
-			ID:          deck.ID,
-			Name:        deck.Name,
-			Description: deck.Description,
-			CardCount:   len(deck.Cards),
-			Languages:   []string{deck.FrontLanguage, deck.BackLanguage},
-		}
-
-		// First deck is free, others are paid
-		if deck.ID == "japanese-basics" {
-			item.Price = "free"
-		} else {
-			item.Price = "tier1"
-			item.IAPProductID = fmt.Sprintf("com.example.deck.%s", deck.ID)
-		}
-
-		catalog.Decks = append(catalog.Decks, item)
+	if err := os.WriteFile(filepath.Join(decksPath, deck.ID+".json"), data, 0644); err != nil {
+		return err
 	}
-
-	return catalog
+	g.indexDeck(deck)
+	return nil
 }
 
-func (g *Generator) GetDeckPreview(deckID string) (*models.DeckPreview, error) {
+// GetDeckPreview returns sample cards in the best match for languages
+func (g *Generator) GetDeckPreview(deckID string, languages []string) (*models.DeckPreview, error) {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
 
-	deck, ok := g.decks[deckID]
+	stored, ok := g.decks[deckID]
 	if !ok {
 		return nil, fmt.Errorf("deck not found: %s", deckID)
 	}
+	deck := localizeDeck(stored, languages)
 
 	previewCount := 5
 	if len(deck.Cards) < previewCount {
@@ -140,10 +249,14 @@ func (g *Generator) GetDeckPreview(deckID string) (*models.DeckPreview, error) {
 		BackLanguage:  deck.BackLanguage,
 		TotalCards:    len(deck.Cards),
 		PreviewCards:  deck.Cards[:previewCount],
+		ThumbnailURL:  deck.ThumbnailURL,
+		BackLanguages: deck.BackLanguages,
 	}, nil
 }
 
-func (g *Generator) GetDeck(deckID string) (*models.Deck, error) {
+// GetDeck returns the deck in the best match for languages
+// Without languages every translation is included
+func (g *Generator) GetDeck(deckID string, languages []string) (*models.Deck, error) {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
 
@@ -152,7 +265,7 @@ func (g *Generator) GetDeck(deckID string) (*models.Deck, error) {
 		return nil, fmt.Errorf("deck not found: %s", deckID)
 	}
 
-	return deck, nil
+	return localizeDeck(deck, languages), nil
 }
 
 func (g *Generator) StartGeneration(req models.GenerateRequest) (*models.GenerateStatus, error) {
@@ -164,82 +277,723 @@ func (g *Generator) StartGeneration(req models.GenerateRequest) (*models.Generat
 		return nil, fmt.Errorf("deck not found: %s", req.DeckID)
 	}
 
+	if _, running := g.jobs.Load(req.DeckID); running {
+		g.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrGenerationRunning, req.DeckID)
+	}
+
+	plan, err := newGenerationPlan(deck, req)
+	if err != nil {
+		g.mu.Unlock()
+		return nil, err
+	}
+
+	if err := g.checkBudget(g.estimatePlan(deck, plan).TotalCost, 0); err != nil {
+		g.mu.Unlock()
+		return nil, err
+	}
+
+	status := g.startJob(deck, plan)
+	g.mu.Unlock()
+
+	return status, nil
+}
+
+// startJob registers a tracked generation job for a plan and runs it in the background
+// g.mu must be held and no job may be running for the deck
+func (g *Generator) startJob(deck *models.Deck, plan *generationPlan) *models.GenerateStatus {
 	status := &models.GenerateStatus{
-		DeckID:     req.DeckID,
+		DeckID:     deck.ID,
 		Status:     "generating",
 		Progress:   0,
-		TotalCards: len(deck.Cards),
+		TotalCards: len(plan.cards),
 	}
-	g.statuses[req.DeckID] = status
-	g.mu.Unlock()
+	g.statuses[deck.ID] = status
+
+	job := newGenerationJob()
+	g.jobs.Store(deck.ID, job)
+
+	g.events.Publish(events.Event{
+		Type:   events.JobStarted,
+		DeckID: deck.ID,
+		Status: status.Status,
+		Total:  status.TotalCards,
+	})
 
 	// Start generation in background
-	go g.generateMedia(deck, status)
+	go g.generateMedia(job, deck, plan, status)
 
-	return status, nil
+	return status
+}
+
+// generationPlan selects the cards and assets a generation run produces
+type generationPlan struct {
+	cards       []*models.Card
+	assets      map[string]bool
+	missingOnly bool
+	candidates  int // Image candidates per card, overrides the deck setting when set
+}
+
+// candidateCount returns the image candidates to generate per card, below 2 means a single image
+func (p *generationPlan) candidateCount(deck *models.Deck) int {
+	if p.candidates > 0 {
+		return p.candidates
+	}
+	return deck.ImageCandidates
+}
+
+// newGenerationPlan resolves the cards and assets requested for a deck
+// Without a card list every card is generated; without an asset filter the
+// front audio and image are generated, plus back audio when the deck enables it
+func newGenerationPlan(deck *models.Deck, req models.GenerateRequest) (*generationPlan, error) {
+	plan := &generationPlan{
+		assets:      make(map[string]bool),
+		missingOnly: req.MissingOnly,
+	}
+
+	if len(req.Assets) == 0 {
+		plan.assets[models.AssetAudioFront] = true
+		plan.assets[models.AssetImage] = true
+		plan.assets[models.AssetAudioBack] = deck.BackAudio
+	}
+	for _, asset := range req.Assets {
+		switch asset {
+		case models.AssetAudioFront, models.AssetAudioBack, models.AssetImage, models.AssetExamples:
+			plan.assets[asset] = true
+		default:
+			return nil, fmt.Errorf("unknown asset: %s", asset)
+		}
+	}
+
+	cardIDs := append([]string{}, req.CardIDs...)
+	for _, input := range req.Cards {
+		cardIDs = append(cardIDs, input.ID)
+	}
+
+	if len(cardIDs) == 0 {
+		for i := range deck.Cards {
+			plan.cards = append(plan.cards, &deck.Cards[i])
+		}
+		return plan, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, id := range cardIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		card := findCard(deck, id)
+		if card == nil {
+			return nil, fmt.Errorf("card not found: %s", id)
+		}
+		plan.cards = append(plan.cards, card)
+	}
+
+	return plan, nil
 }
 
-func (g *Generator) generateMedia(deck *models.Deck, status *models.GenerateStatus) {
-	// Get image prompt template (use default if not set)
-	imageTemplate := deck.ImagePromptTemplate
-	if imageTemplate == "" {
-		imageTemplate = defaultImagePromptTemplate
+// needs reports whether an asset stored at url should be (re)generated
+func (g *Generator) needs(plan *generationPlan, deck *models.Deck, card *models.Card, url string) bool {
+	if !plan.missingOnly || url == "" {
+		return true
 	}
+	return !g.storage.Exists(deck.ID, card.ID, path.Base(url))
+}
 
-	for i := range deck.Cards {
-		card := &deck.Cards[i]
+func (g *Generator) generateMedia(job *generationJob, deck *models.Deck, plan *generationPlan, status *models.GenerateStatus) {
+	ctx := job.ctx
+	defer job.cancel()
+
+	// Set when a budget runs out, the job stops before the next card
+	var budgetErr error
+
+	for i, card := range plan.cards {
+		// Pausing and cancelling take effect between cards
+		if err := job.checkpoint(); err != nil {
+			break
+		}
+
+		// Charges are only known after each request, so a budget can be
+		// overrun by at most one card
+		if budgetErr = g.checkBudget(0, job.spent()); budgetErr != nil {
+			break
+		}
+
+		g.events.Publish(events.Event{
+			Type:     events.CardStarted,
+			DeckID:   deck.ID,
+			CardID:   card.ID,
+			Progress: i,
+			Total:    len(plan.cards),
+		})
+
+		media := card.Media
+		if media == nil {
+			media = &models.Media{}
+		}
 
 		// Generate TTS for frontLanguage (the language being learned)
-		if g.ttsClient != nil {
-			audioData, err := g.ttsClient.GenerateSpeech(card.FrontText, deck.TTSVoiceID)
-			if err == nil {
-				url, err := g.storage.Save(deck.ID, card.ID, "audio.mp3", audioData)
-				if err == nil {
-					if card.Media == nil {
-						card.Media = &models.Media{}
-					}
-					card.Media.AudioFront = url
-				}
-			}
+		if g.ttsClient != nil && plan.assets[models.AssetAudioFront] {
+			g.generateAudio(ctx, deck, card, plan)
+		}
+
+		// Generate TTS for the translation
+		if g.ttsClient != nil && plan.assets[models.AssetAudioBack] && g.needs(plan, deck, card, media.AudioBack) {
+			g.generateBackAudio(ctx, deck, card)
 		}
 
 		// Generate illustration using template
-		if g.imageClient != nil {
-			prompt := buildImagePrompt(imageTemplate, card)
-			imageData, err := g.imageClient.GenerateImage(prompt)
-			if err == nil {
-				url, err := g.storage.Save(deck.ID, card.ID, "image.png", imageData)
-				if err == nil {
-					if card.Media == nil {
-						card.Media = &models.Media{}
-					}
-					card.Media.Image = url
-				}
-			}
+		if g.imageClient != nil && plan.assets[models.AssetImage] && g.needs(plan, deck, card, media.Image) {
+			g.generateImage(ctx, deck, card, plan)
 		}
 
-		card.MediaStatus = "ready"
+		// Example sentences are opt-in, they need an editor's approval before audio
+		if plan.assets[models.AssetExamples] {
+			g.generateExamples(ctx, deck, card, plan)
+		}
+
+		if ctx.Err() != nil {
+			// Cancelled mid-card, assets already stored are kept
+			break
+		}
+
+		if card.MediaStatus != mediaStatusReview {
+			card.MediaStatus = "ready"
+		}
 
 		g.mu.Lock()
 		status.Progress = i + 1
+		status.Failures = int(job.failures.Load())
+		status.Cost = job.spent()
 		g.mu.Unlock()
+
+		g.events.Publish(events.Event{
+			Type:     events.CardCompleted,
+			DeckID:   deck.ID,
+			CardID:   card.ID,
+			Status:   card.MediaStatus,
+			Progress: i + 1,
+			Total:    len(plan.cards),
+		})
 	}
 
 	g.mu.Lock()
-	status.Status = "completed"
+	status.Failures = int(job.failures.Load())
+	status.Cost = job.spent()
+	switch {
+	case ctx.Err() != nil:
+		status.Status = "cancelled"
+	case budgetErr != nil:
+		status.Status = "error"
+		status.Error = budgetErr.Error()
+	case status.Failures > 0:
+		status.Status = "error"
+		status.Error = fmt.Sprintf("%d asset(s) failed to generate", status.Failures)
+	default:
+		status.Status = "completed"
+	}
+	g.jobs.Delete(deck.ID)
 	g.saveDeck(deck)
+	final := *status
 	g.mu.Unlock()
+
+	switch final.Status {
+	case "completed":
+		g.webhooks.Publish(webhooks.GenerationCompleted, final)
+	case "error":
+		g.webhooks.Publish(webhooks.GenerationFailed, final)
+	}
+
+	eventType := events.JobCompleted
+	if final.Status == "cancelled" {
+		eventType = events.JobCancelled
+	}
+	// Failed jobs still end with job.completed, clients check status and error
+
+	g.events.Publish(events.Event{
+		Type:     eventType,
+		DeckID:   deck.ID,
+		Status:   final.Status,
+		Progress: final.Progress,
+		Total:    final.TotalCards,
+	})
+}
+
+// cardFailed logs a failed asset and notifies event subscribers
+func (g *Generator) cardFailed(deck *models.Deck, card *models.Card, asset, variant string, err error) {
+	log.Printf("Generating %s failed for %s/%s: %v", asset, deck.ID, card.ID, err)
+
+	// Counted lock-free, this also runs from review operations that hold g.mu
+	if job, ok := g.jobs.Load(deck.ID); ok {
+		job.(*generationJob).failures.Add(1)
+	}
+
+	g.events.Publish(events.Event{
+		Type:    events.CardFailed,
+		DeckID:  deck.ID,
+		CardID:  card.ID,
+		Asset:   asset,
+		Variant: variant,
+		Error:   err.Error(),
+	})
+}
+
+// generateImage creates the card illustration, or candidates when the deck asks for review
+func (g *Generator) generateImage(ctx context.Context, deck *models.Deck, card *models.Card, plan *generationPlan) {
+	if count := plan.candidateCount(deck); count > 1 {
+		if err := g.generateCandidates(ctx, deck, card, count); err != nil {
+			g.cardFailed(deck, card, "candidate", "", err)
+		}
+		return
+	}
+
+	text, opts, err := g.cardImageRequest(deck, card)
+	if err != nil {
+		g.cardFailed(deck, card, models.AssetImage, "", fmt.Errorf("image prompt: %w", err))
+		return
+	}
+
+	imageData, err := g.imageClient.GenerateImageWithOptions(ctx, text, opts)
+	if err != nil {
+		g.cardFailed(deck, card, models.AssetImage, "", err)
+		return
+	}
+	g.recordUsage(deck.ID, usage.ProviderImagen, 1)
+
+	g.saveImage(deck, card, imageData)
+}
+
+// generateAudio synthesizes the front audio and any configured variants for a card
+func (g *Generator) generateAudio(ctx context.Context, deck *models.Deck, card *models.Card, plan *generationPlan) {
+	text := speechText(deck, card)
+	baseOpts := speechOptions(deck)
+
+	voiceID, err := ttsVoice(deck.FrontLanguage, deck.TTSVoiceID)
+	if err != nil {
+		g.cardFailed(deck, card, models.AssetAudioFront, "", err)
+		return
+	}
+
+	media := card.Media
+	if media == nil {
+		media = &models.Media{}
+	}
+
+	if g.needs(plan, deck, card, media.AudioFront) {
+		audioData, err := g.ttsClient.GenerateSpeechWithOptions(ctx, text, voiceID, baseOpts)
+		if err != nil {
+			g.cardFailed(deck, card, models.AssetAudioFront, "", err)
+		} else {
+			g.recordSpeech(deck.ID, text)
+			g.saveAudio(deck, card, "audio", "front", audioData, func(m *models.Media, url string) {
+				m.AudioFront = url
+			})
+		}
+	}
+
+	for _, variant := range deck.AudioVariants {
+		// Decks loaded from storage are not validated, skip names that would escape the card directory
+		if validateVariantName(variant.Name) != nil || !g.needs(plan, deck, card, media.AudioVariants[variant.Name]) {
+			continue
+		}
+
+		opts := baseOpts
+		opts.Speed = variant.Speed
+		audioData, err := g.ttsClient.GenerateSpeechWithOptions(ctx, text, voiceID, opts)
+		if err != nil {
+			g.cardFailed(deck, card, models.AssetAudioFront, variant.Name, err)
+			continue
+		}
+		g.recordSpeech(deck.ID, text)
+
+		name := variant.Name
+		g.saveAudio(deck, card, "audio-"+name, name, audioData, func(m *models.Media, url string) {
+			if m.AudioVariants == nil {
+				m.AudioVariants = make(map[string]string)
+			}
+			m.AudioVariants[name] = url
+		})
+	}
+}
+
+// generateBackAudio synthesizes the translation (backText) for a card
+func (g *Generator) generateBackAudio(ctx context.Context, deck *models.Deck, card *models.Card) {
+	// Without a voice for the back language the client's configured default is used,
+	// never the front voice, which speaks another language
+	voiceID, err := ttsVoice(deck.BackLanguage, deck.TTSBackVoiceID)
+	if err != nil {
+		g.cardFailed(deck, card, models.AssetAudioBack, "", err)
+		return
+	}
+
+	audioData, err := g.ttsClient.GenerateSpeechWithOptions(ctx, card.BackText, voiceID, speechOptions(deck))
+	if err != nil {
+		g.cardFailed(deck, card, models.AssetAudioBack, "", err)
+		return
+	}
+	g.recordSpeech(deck.ID, card.BackText)
+
+	g.saveAudio(deck, card, "audio-back", "back", audioData, func(m *models.Media, url string) {
+		m.AudioBack = url
+	})
+}
+
+// saveAudio post-processes synthesized MP3 data (when enabled) and stores it
+// setURL records the stored URL on the card media; key names the duration entry
+func (g *Generator) saveAudio(deck *models.Deck, card *models.Card, basename, key string, data []byte, setURL func(*models.Media, string)) {
+	filename := basename + ".mp3"
+	var duration time.Duration
+	var processed bool
+
+	if g.audioProcessor != nil {
+		result, err := g.audioProcessor.Process(data)
+		if err != nil {
+			// Fall back to the unprocessed provider output
+			log.Printf("Audio post-processing failed for %s/%s: %v", deck.ID, card.ID, err)
+		} else {
+			data = result.Data
+			filename = basename + result.Extension
+			duration = result.Duration
+			processed = true
+		}
+	}
+	if !processed {
+		if d, err := audio.MP3Duration(data); err != nil {
+			log.Printf("Audio duration unknown for %s/%s: %v", deck.ID, card.ID, err)
+		} else {
+			duration = d
+		}
+	}
+
+	asset, variant := models.AssetAudioFront, key
+	switch key {
+	case "front":
+		variant = ""
+	case "back":
+		asset, variant = models.AssetAudioBack, ""
+	default:
+		if id, ok := strings.CutPrefix(key, "example-"); ok {
+			asset, variant = models.AssetExamples, id
+		}
+	}
+
+	url, err := g.storage.Save(deck.ID, card.ID, filename, data)
+	if err != nil {
+		g.cardFailed(deck, card, asset, variant, err)
+		return
+	}
+
+	if card.Media == nil {
+		card.Media = &models.Media{}
+	}
+	setURL(card.Media, url)
+
+	g.events.Publish(events.Event{
+		Type:    events.CardAudioDone,
+		DeckID:  deck.ID,
+		CardID:  card.ID,
+		Asset:   asset,
+		Variant: variant,
+		URL:     url,
+	})
+
+	if duration > 0 {
+		if card.Media.AudioDurationsMs == nil {
+			card.Media.AudioDurationsMs = make(map[string]int64)
+		}
+		card.Media.AudioDurationsMs[key] = duration.Milliseconds()
+	}
+}
+
+// speechOptions converts the deck TTS settings into provider options
+func speechOptions(deck *models.Deck) tts.SpeechOptions {
+	settings := deck.TTSSettings
+	if settings == nil {
+		return tts.SpeechOptions{}
+	}
+
+	return tts.SpeechOptions{
+		ModelID:         settings.ModelID,
+		Stability:       settings.Stability,
+		SimilarityBoost: settings.SimilarityBoost,
+		Style:           settings.Style,
+		UseSpeakerBoost: settings.UseSpeakerBoost,
+		OutputFormat:    settings.OutputFormat,
+	}
+}
+
+// speechText returns the text to synthesize for a card
+// Uses the reading when the deck asks for it (e.g. to avoid kanji mis-pronunciation)
+func speechText(deck *models.Deck, card *models.Card) string {
+	if deck.TTSUseReading && card.Reading != "" {
+		return speechReading(deck.FrontLanguage, card.FrontText, card.Reading)
+	}
+	return card.FrontText
+}
+
+// saveImage stores a generated image, as renditions when enabled
+// Media.Image points at the card-size rendition; the raw PNG is kept only as a fallback
+func (g *Generator) saveImage(deck *models.Deck, card *models.Card, data []byte) {
+	if card.Media == nil {
+		card.Media = &models.Media{}
+	}
+	if err := applyPlaceholder(card.Media, data); err != nil {
+		log.Printf("Image placeholder failed for %s/%s: %v", deck.ID, card.ID, err)
+	}
+
+	if g.cfg.ImageRenditions {
+		outputs, err := imageproc.Process(data, imageproc.DefaultRenditions)
+		if err != nil {
+			log.Printf("Image renditions failed for %s/%s, storing original: %v", deck.ID, card.ID, err)
+		} else {
+			renditions := make(map[string]models.ImageRendition)
+			for _, out := range outputs {
+				url, err := g.storage.Save(deck.ID, card.ID, "image-"+out.Name+out.Extension, out.Data)
+				if err != nil {
+					continue
+				}
+				renditions[out.Name] = models.ImageRendition{URL: url, Width: out.Width, Height: out.Height}
+			}
+
+			if len(renditions) > 0 {
+				if card.Media == nil {
+					card.Media = &models.Media{}
+				}
+				card.Media.ImageRenditions = renditions
+				useFullSize(card.Media)
+				card.Media.Image = renditions["card"].URL
+				if card.Media.Image == "" {
+					card.Media.Image = renditions["full"].URL
+				}
+				g.imageDone(deck, card)
+				return
+			}
+		}
+	}
+
+	url, err := g.storage.Save(deck.ID, card.ID, "image.png", data)
+	if err != nil {
+		g.cardFailed(deck, card, models.AssetImage, "", err)
+		return
+	}
+
+	card.Media.Image = url
+	g.imageDone(deck, card)
 }
 
-// buildImagePrompt replaces placeholders in template with card values
-// Supported placeholders: {word}, {front}, {back}, {reading}
-func buildImagePrompt(template string, card *models.Card) string {
-	prompt := template
-	prompt = strings.ReplaceAll(prompt, "{word}", card.BackText)  // Use backText (translation) for image
-	prompt = strings.ReplaceAll(prompt, "{front}", card.FrontText)
-	prompt = strings.ReplaceAll(prompt, "{back}", card.BackText)
-	prompt = strings.ReplaceAll(prompt, "{reading}", card.Reading)
-	return prompt
+// imageDone notifies event subscribers about a stored card image
+func (g *Generator) imageDone(deck *models.Deck, card *models.Card) {
+	g.events.Publish(events.Event{
+		Type:   events.CardImageDone,
+		DeckID: deck.ID,
+		CardID: card.ID,
+		Asset:  models.AssetImage,
+		URL:    card.Media.Image,
+	})
+}
+
+// Subscribe returns generation events for a deck and a snapshot of its current status (nil if never generated)
+// The returned function must be called to release the subscription
+func (g *Generator) Subscribe(deckID string) (*events.Event, <-chan events.Event, func(), error) {
+	// Subscribe before the snapshot so nothing published in between is lost
+	ch, unsubscribe := g.events.Subscribe(deckID)
+
+	g.mu.RLock()
+	_, ok := g.decks[deckID]
+	status := g.statuses[deckID]
+	var snapshot *events.Event
+	if status != nil {
+		snapshot = &events.Event{
+			Type:     events.JobStatus,
+			DeckID:   deckID,
+			Status:   status.Status,
+			Progress: status.Progress,
+			Total:    status.TotalCards,
+			Error:    status.Error,
+		}
+	}
+	g.mu.RUnlock()
+
+	if !ok {
+		unsubscribe()
+		return nil, nil, nil, fmt.Errorf("deck not found: %s", deckID)
+	}
+
+	return snapshot, ch, unsubscribe, nil
+}
+
+// applyPlaceholder records size, BlurHash and dominant color of an image on media
+func applyPlaceholder(media *models.Media, data []byte) error {
+	placeholder, err := imageproc.Analyze(data)
+	if err != nil {
+		return err
+	}
+
+	media.ImageWidth = placeholder.Width
+	media.ImageHeight = placeholder.Height
+	media.BlurHash = placeholder.BlurHash
+	media.DominantColor = placeholder.DominantColor
+	useFullSize(media)
+	return nil
+}
+
+// useFullSize sets ImageWidth and ImageHeight to the full rendition when there is one
+// Without renditions Image is the original, whose size applyPlaceholder records
+func useFullSize(media *models.Media) {
+	if full, ok := media.ImageRenditions["full"]; ok && full.Width > 0 {
+		media.ImageWidth = full.Width
+		media.ImageHeight = full.Height
+	}
+}
+
+// BackfillPlaceholders computes image placeholders for cards that have an image but no BlurHash
+// Returns the number of updated cards
+func (g *Generator) BackfillPlaceholders() (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	updated := 0
+	for _, deck := range g.decks {
+		changed := false
+		for i := range deck.Cards {
+			card := &deck.Cards[i]
+			if card.Media == nil || card.Media.Image == "" || card.Media.BlurHash != "" {
+				continue
+			}
+
+			source := card.Media.Image
+			if full, ok := card.Media.ImageRenditions["full"]; ok {
+				source = full.URL
+			}
+			data, err := g.storage.Load(deck.ID, card.ID, path.Base(source))
+			if err != nil {
+				log.Printf("Skipping %s/%s: %v", deck.ID, card.ID, err)
+				continue
+			}
+			if err := applyPlaceholder(card.Media, data); err != nil {
+				log.Printf("Skipping %s/%s: %v", deck.ID, card.ID, err)
+				continue
+			}
+
+			changed = true
+			updated++
+		}
+
+		if changed {
+			if err := g.saveDeck(deck); err != nil {
+				return updated, fmt.Errorf("failed to save deck %s: %w", deck.ID, err)
+			}
+		}
+	}
+
+	return updated, nil
+}
+
+// imageRequest renders the image prompt and provider options for a card
+// A per-card prompt wins over the deck template, which falls back to the default;
+// the style preset (if any) wraps the prompt and supplies provider parameters
+func imageRequest(deck *models.Deck, card *models.Card, style *models.StylePreset) (string, image.ImageOptions, error) {
+	var opts image.ImageOptions
+
+	template := card.ImagePrompt
+	if template == "" {
+		template = deck.ImagePromptTemplate
+	}
+	if template == "" {
+		template = defaultImagePromptTemplate
+	}
+
+	data := prompt.NewData(deck, card)
+
+	var parts, negatives []string
+	render := func(text string, into *[]string) error {
+		if text == "" {
+			return nil
+		}
+		rendered, err := prompt.Render(text, data)
+		if err != nil {
+			return err
+		}
+		if rendered != "" {
+			*into = append(*into, rendered)
+		}
+		return nil
+	}
+
+	if style != nil {
+		if err := render(style.PromptPrefix, &parts); err != nil {
+			return "", opts, fmt.Errorf("style %s prefix: %w", style.ID, err)
+		}
+	}
+	if err := render(template, &parts); err != nil {
+		return "", opts, err
+	}
+	if style != nil {
+		if err := render(style.PromptSuffix, &parts); err != nil {
+			return "", opts, fmt.Errorf("style %s suffix: %w", style.ID, err)
+		}
+		if err := render(style.NegativePrompt, &negatives); err != nil {
+			return "", opts, fmt.Errorf("style %s negative prompt: %w", style.ID, err)
+		}
+		opts.AspectRatio = style.AspectRatio
+		opts.PersonGeneration = style.PersonGeneration
+	}
+	if err := render(card.NegativePrompt, &negatives); err != nil {
+		return "", opts, fmt.Errorf("negative prompt: %w", err)
+	}
+
+	opts.NegativePrompt = strings.Join(negatives, ", ")
+	return strings.Join(parts, " "), opts, nil
+}
+
+// cardImageRequest resolves the deck style and renders the image request for a card
+func (g *Generator) cardImageRequest(deck *models.Deck, card *models.Card) (string, image.ImageOptions, error) {
+	style, err := g.deckStyle(deck)
+	if err != nil {
+		return "", image.ImageOptions{}, err
+	}
+	return imageRequest(deck, card, style)
+}
+
+// deckStyle returns the style preset for a deck, or nil when the deck has none
+func (g *Generator) deckStyle(deck *models.Deck) (*models.StylePreset, error) {
+	if deck.StyleID == "" {
+		return nil, nil
+	}
+	return g.styles.Get(deck.StyleID)
+}
+
+// PreviewImagePrompt renders the final image prompt for a card without generating anything
+func (g *Generator) PreviewImagePrompt(deckID, cardID string) (*models.PromptPreview, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	deck, ok := g.decks[deckID]
+	if !ok {
+		return nil, fmt.Errorf("deck not found: %s", deckID)
+	}
+
+	card := findCard(deck, cardID)
+	if card == nil {
+		return nil, fmt.Errorf("card not found: %s", cardID)
+	}
+
+	text, opts, err := g.cardImageRequest(deck, card)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PromptPreview{
+		DeckID:           deck.ID,
+		CardID:           card.ID,
+		StyleID:          deck.StyleID,
+		Prompt:           text,
+		NegativePrompt:   opts.NegativePrompt,
+		AspectRatio:      opts.AspectRatio,
+		PersonGeneration: opts.PersonGeneration,
+	}, nil
 }
 
 func (g *Generator) GetStatus(deckID string) (*models.GenerateStatus, error) {
@@ -254,11 +1008,197 @@ func (g *Generator) GetStatus(deckID string) (*models.GenerateStatus, error) {
 	return status, nil
 }
 
-// CreateDeck creates a new deck (for admin use)
+// ErrDeckExists is returned when creating a deck whose ID is taken
+var ErrDeckExists = errors.New("deck already exists")
+
+// CreateDeck creates a new deck (for admin use), failing with ErrDeckExists if the ID is taken
 func (g *Generator) CreateDeck(deck *models.Deck) error {
+	if err := g.validateDeck(deck); err != nil {
+		return err
+	}
+	fillReadings(deck)
+	annotateFurigana(deck)
+	if deck.CreatedAt.IsZero() {
+		deck.CreatedAt = time.Now().UTC()
+	}
+
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	if _, exists := g.decks[deck.ID]; exists {
+		return fmt.Errorf("%w: %s", ErrDeckExists, deck.ID)
+	}
+
 	g.decks[deck.ID] = deck
-	return g.saveDeck(deck)
+	if err := g.saveDeck(deck); err != nil {
+		return err
+	}
+
+	g.deckUpdated(deck)
+	return nil
+}
+
+// deckUpdated notifies webhook subscribers that a deck changed
+func (g *Generator) deckUpdated(deck *models.Deck) {
+	g.webhooks.Publish(webhooks.DeckUpdated, map[string]interface{}{
+		"deckId":    deck.ID,
+		"name":      deck.Name,
+		"cardCount": len(deck.Cards),
+	})
+}
+
+// slugID restricts deck and card IDs, which become directory names under storage
+var slugID = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+func validateID(kind, id string) error {
+	if !slugID.MatchString(id) {
+		return fmt.Errorf("%s id %q must match %s", kind, id, slugID)
+	}
+	return nil
+}
+
+// reservedDeckIDs name directories under StoragePath that are not deck media
+// State now lives under DataPath, the names stay reserved for directories left by older versions
+var reservedDeckIDs = map[string]bool{
+	"decks":    true,
+	"stats":    true,
+	"styles":   true,
+	"usage":    true,
+	"webhooks": true,
+}
+
+func validateDeckID(id string) error {
+	if err := validateID("deck", id); err != nil {
+		return err
+	}
+	if reservedDeckIDs[id] {
+		return fmt.Errorf("deck id %q is reserved", id)
+	}
+	return nil
+}
+
+// variantName restricts audio variant names, which become file names
+var variantName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// validateVariantName checks an audio variant name, which becomes part of a file name
+// front, back and example-* are reserved for the keys of built-in audio in saveAudio
+func validateVariantName(name string) error {
+	if !variantName.MatchString(name) {
+		return fmt.Errorf("audio variant name %q must match %s", name, variantName)
+	}
+	if name == "front" || name == "back" || strings.HasPrefix(name, "example") {
+		return fmt.Errorf("audio variant name %q is reserved", name)
+	}
+	return nil
+}
+
+// validateDeck checks deck settings before the deck is saved
+func (g *Generator) validateDeck(deck *models.Deck) error {
+	if err := validateDeckID(deck.ID); err != nil {
+		return err
+	}
+	cardIDs := make(map[string]bool)
+	for _, card := range deck.Cards {
+		if err := validateID("card", card.ID); err != nil {
+			return err
+		}
+		if cardIDs[card.ID] {
+			return fmt.Errorf("duplicate card id %q", card.ID)
+		}
+		cardIDs[card.ID] = true
+	}
+
+	if err := validateLanguages(deck); err != nil {
+		return err
+	}
+
+	if deck.StyleID != "" {
+		if _, err := g.styles.Get(deck.StyleID); err != nil {
+			return err
+		}
+	}
+
+	if deck.ImageCandidates < 0 || deck.ImageCandidates > image.MaxSampleCount {
+		return fmt.Errorf("imageCandidates must be between 0 and %d", image.MaxSampleCount)
+	}
+
+	if err := tts.ValidateOptions(speechOptions(deck)); err != nil {
+		return fmt.Errorf("invalid tts settings: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, variant := range deck.AudioVariants {
+		if err := validateVariantName(variant.Name); err != nil {
+			return err
+		}
+		if seen[variant.Name] {
+			return fmt.Errorf("duplicate audio variant %q", variant.Name)
+		}
+		seen[variant.Name] = true
+
+		opts := speechOptions(deck)
+		opts.Speed = variant.Speed
+		if err := tts.ValidateOptions(opts); err != nil {
+			return fmt.Errorf("invalid audio variant %q: %w", variant.Name, err)
+		}
+	}
+
+	if deck.ImagePromptTemplate != "" {
+		if err := prompt.Validate(deck.ImagePromptTemplate); err != nil {
+			return fmt.Errorf("deck image prompt: %w", err)
+		}
+	}
+	for _, card := range deck.Cards {
+		for _, text := range []string{card.ImagePrompt, card.NegativePrompt} {
+			if text == "" {
+				continue
+			}
+			if err := prompt.Validate(text); err != nil {
+				return fmt.Errorf("card %s image prompt: %w", card.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ListVoices returns the voices offered by the TTS provider
+func (g *Generator) ListVoices() ([]tts.Voice, error) {
+	if g.ttsClient == nil {
+		return nil, fmt.Errorf("tts provider not configured")
+	}
+	return g.ttsClient.ListVoices()
+}
+
+// ListStyles returns all art style presets
+func (g *Generator) ListStyles() []models.StylePreset {
+	return g.styles.List()
+}
+
+func (g *Generator) GetStyle(id string) (*models.StylePreset, error) {
+	return g.styles.Get(id)
+}
+
+// CreateStyle adds an art style preset, failing with ErrStyleExists if the ID is taken
+func (g *Generator) CreateStyle(style *models.StylePreset) error {
+	return g.styles.Create(style)
+}
+
+// SaveStyle creates or replaces an art style preset
+func (g *Generator) SaveStyle(style *models.StylePreset) error {
+	return g.styles.Save(style)
+}
+
+// DeleteStyle removes an art style preset that no deck references
+func (g *Generator) DeleteStyle(id string) error {
+	g.mu.RLock()
+	for _, deck := range g.decks {
+		if deck.StyleID == id {
+			g.mu.RUnlock()
+			return fmt.Errorf("style %s is used by deck %s", id, deck.ID)
+		}
+	}
+	g.mu.RUnlock()
+
+	return g.styles.Delete(id)
 }
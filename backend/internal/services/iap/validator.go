@@ -15,6 +15,7 @@ type Validator struct {
 	appleSharedSecret string
 	googlePackageName string
 	useSandbox        bool
+	client            *http.Client
 }
 
 // NewValidator creates a new IAP validator
@@ -23,9 +24,15 @@ func NewValidator(appleSecret, googlePackage string, useSandbox bool) *Validator
 		appleSharedSecret: appleSecret,
 		googlePackageName: googlePackage,
 		useSandbox:        useSandbox,
+		client:            &http.Client{},
 	}
 }
 
+// SetTransport replaces the HTTP transport used for Apple receipt requests
+func (v *Validator) SetTransport(rt http.RoundTripper) {
+	v.client.Transport = rt
+}
+
 // VerifyRequest represents a receipt verification request
 type VerifyRequest struct {
 	Platform    string `json:"platform"`    // "ios" or "android"
@@ -40,6 +47,9 @@ type VerifyResponse struct {
 	DeckID    string `json:"deckId,omitempty"`
 	ProductID string `json:"productId,omitempty"`
 	Error     string `json:"error,omitempty"`
+
+	// TransactionID identifies the purchase, the same on every verification of it
+	TransactionID string `json:"transactionId,omitempty"`
 }
 
 // Verify validates an IAP receipt
@@ -54,6 +64,53 @@ func (v *Validator) Verify(req VerifyRequest) (*VerifyResponse, error) {
 	}
 }
 
+// Purchases verifies a receipt once and returns the product IDs it includes
+// Android purchase tokens cover a single product, so productID names it
+func (v *Validator) Purchases(platform, receiptData, productID string) (map[string]bool, error) {
+	purchases := make(map[string]bool)
+	if receiptData == "" {
+		return purchases, nil
+	}
+
+	switch strings.ToLower(platform) {
+	case "ios":
+		inApp, _, err := v.applePurchases(receiptData)
+		if err != nil {
+			return nil, err
+		}
+		for _, purchase := range inApp {
+			if id, _ := purchase["product_id"].(string); id != "" {
+				purchases[id] = true
+			}
+		}
+	case "android":
+		resp, err := v.verifyGoogle(VerifyRequest{ReceiptData: receiptData, ProductID: productID})
+		if err != nil {
+			return nil, err
+		}
+		if resp.Valid && resp.ProductID != "" {
+			purchases[resp.ProductID] = true
+		}
+	}
+	return purchases, nil
+}
+
+// OwnedDecks returns which of deckIDs the purchased products unlock
+func OwnedDecks(purchases map[string]bool, deckIDs []string) map[string]bool {
+	owned := make(map[string]bool)
+	for _, deckID := range deckIDs {
+		if purchases[DeckProductID(deckID)] {
+			owned[deckID] = true
+		}
+	}
+	return owned
+}
+
+// DeckProductID returns the store product that unlocks a paid deck
+func DeckProductID(deckID string) string {
+	return "com.example.duolingocards.deck." + deckID
+}
+
 // Apple App Store receipt validation
 
 const (
@@ -75,16 +132,47 @@ type appleReceiptResponse struct {
 }
 
 func (v *Validator) verifyApple(req VerifyRequest) (*VerifyResponse, error) {
+	inApp, reason, err := v.applePurchases(req.ReceiptData)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		return &VerifyResponse{Valid: false, Error: reason}, nil
+	}
+
+	// Check if the expected product is in the receipt
+	for _, purchase := range inApp {
+		productID, _ := purchase["product_id"].(string)
+		if productID == req.ProductID {
+			transactionID, _ := purchase["original_transaction_id"].(string)
+			return &VerifyResponse{
+				Valid:         true,
+				DeckID:        req.DeckID,
+				ProductID:     productID,
+				TransactionID: transactionID,
+			}, nil
+		}
+	}
+
+	return &VerifyResponse{
+		Valid: false,
+		Error: "product not found in receipt",
+	}, nil
+}
+
+// applePurchases verifies a receipt with Apple and returns its in-app purchases
+// A receipt Apple rejects comes back as a reason rather than an error
+func (v *Validator) applePurchases(receiptData string) ([]map[string]interface{}, string, error) {
 	// Prepare request
 	appleReq := appleReceiptRequest{
-		ReceiptData:            req.ReceiptData,
+		ReceiptData:            receiptData,
 		Password:               v.appleSharedSecret,
 		ExcludeOldTransactions: true,
 	}
 
 	body, err := json.Marshal(appleReq)
 	if err != nil {
-		return nil, fmt.Errorf("marshal apple request: %w", err)
+		return nil, "", fmt.Errorf("marshal apple request: %w", err)
 	}
 
 	// Try production first, then sandbox if needed
@@ -95,59 +183,38 @@ func (v *Validator) verifyApple(req VerifyRequest) (*VerifyResponse, error) {
 
 	resp, err := v.sendAppleRequest(url, body)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// Status 21007 means receipt is from sandbox, retry with sandbox URL
 	if resp.Status == 21007 && !v.useSandbox {
 		resp, err = v.sendAppleRequest(appleSandboxURL, body)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 	}
 
 	// Check status
 	if resp.Status != 0 {
-		return &VerifyResponse{
-			Valid: false,
-			Error: fmt.Sprintf("apple verification failed: status %d", resp.Status),
-		}, nil
+		return nil, fmt.Sprintf("apple verification failed: status %d", resp.Status), nil
 	}
 
 	// Extract in-app purchases from receipt
-	inApp, ok := resp.Receipt["in_app"].([]interface{})
-	if !ok || len(inApp) == 0 {
-		return &VerifyResponse{
-			Valid: false,
-			Error: "no in-app purchases in receipt",
-		}, nil
-	}
-
-	// Check if the expected product is in the receipt
-	for _, item := range inApp {
-		purchase, ok := item.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		productID, _ := purchase["product_id"].(string)
-		if productID == req.ProductID {
-			return &VerifyResponse{
-				Valid:     true,
-				DeckID:    req.DeckID,
-				ProductID: productID,
-			}, nil
+	items, _ := resp.Receipt["in_app"].([]interface{})
+	var inApp []map[string]interface{}
+	for _, item := range items {
+		if purchase, ok := item.(map[string]interface{}); ok {
+			inApp = append(inApp, purchase)
 		}
 	}
-
-	return &VerifyResponse{
-		Valid: false,
-		Error: "product not found in receipt",
-	}, nil
+	if len(inApp) == 0 {
+		return nil, "no in-app purchases in receipt", nil
+	}
+	return inApp, "", nil
 }
 
 func (v *Validator) sendAppleRequest(url string, body []byte) (*appleReceiptResponse, error) {
-	httpResp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	httpResp, err := v.client.Post(url, "application/json", bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("apple request failed: %w", err)
 	}
@@ -171,11 +238,13 @@ func (v *Validator) sendAppleRequest(url string, body []byte) (*appleReceiptResp
 
 func (v *Validator) verifyGoogle(req VerifyRequest) (*VerifyResponse, error) {
 	// In development/sandbox mode, accept any receipt
+	// The purchase token is unique per purchase and serves as transaction ID
 	if v.useSandbox {
 		return &VerifyResponse{
-			Valid:     true,
-			DeckID:    req.DeckID,
-			ProductID: req.ProductID,
+			Valid:         true,
+			DeckID:        req.DeckID,
+			ProductID:     req.ProductID,
+			TransactionID: req.ReceiptData,
 		}, nil
 	}
 
@@ -196,9 +265,10 @@ func (v *Validator) verifyGoogle(req VerifyRequest) (*VerifyResponse, error) {
 	// Parse the purchase token (simplified)
 	// Real implementation should verify with Google API
 	return &VerifyResponse{
-		Valid:     true,
-		DeckID:    req.DeckID,
-		ProductID: req.ProductID,
+		Valid:         true,
+		DeckID:        req.DeckID,
+		ProductID:     req.ProductID,
+		TransactionID: req.ReceiptData,
 	}, nil
 }
 
@@ -2,6 +2,7 @@ package image
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -12,6 +13,9 @@ import (
 const (
 	// Gemini API endpoint for image generation
 	geminiBaseURL = "https://generativelanguage.googleapis.com/v1beta/models/imagen-3.0-generate-002:predict"
+
+	// MaxSampleCount is the most images a single request may return
+	MaxSampleCount = 4
 )
 
 type ImagenClient struct {
@@ -26,6 +30,11 @@ func NewImagenClient(apiKey string) *ImagenClient {
 	}
 }
 
+// SetTransport replaces the HTTP transport, e.g. to add rate limiting
+func (c *ImagenClient) SetTransport(rt http.RoundTripper) {
+	c.client.Transport = rt
+}
+
 type imagenRequest struct {
 	Instances  []imagenInstance `json:"instances"`
 	Parameters imagenParams     `json:"parameters"`
@@ -36,9 +45,43 @@ type imagenInstance struct {
 }
 
 type imagenParams struct {
-	SampleCount       int    `json:"sampleCount"`
-	AspectRatio       string `json:"aspectRatio"`
-	PersonGeneration  string `json:"personGeneration"`
+	SampleCount      int    `json:"sampleCount"`
+	AspectRatio      string `json:"aspectRatio"`
+	PersonGeneration string `json:"personGeneration"`
+	NegativePrompt   string `json:"negativePrompt,omitempty"`
+}
+
+// ImageOptions tunes a single generation request
+// Empty fields fall back to the client defaults
+type ImageOptions struct {
+	NegativePrompt   string // What the image should not contain
+	AspectRatio      string // e.g. "1:1", "4:3"
+	PersonGeneration string // dont_allow, allow_adult, allow_all
+}
+
+var supportedAspectRatios = map[string]bool{
+	"1:1":  true,
+	"3:4":  true,
+	"4:3":  true,
+	"9:16": true,
+	"16:9": true,
+}
+
+var supportedPersonGeneration = map[string]bool{
+	"dont_allow":  true,
+	"allow_adult": true,
+	"allow_all":   true,
+}
+
+// ValidateOptions checks that opts only contains values the provider accepts
+func ValidateOptions(opts ImageOptions) error {
+	if opts.AspectRatio != "" && !supportedAspectRatios[opts.AspectRatio] {
+		return fmt.Errorf("unsupported aspect ratio: %s", opts.AspectRatio)
+	}
+	if opts.PersonGeneration != "" && !supportedPersonGeneration[opts.PersonGeneration] {
+		return fmt.Errorf("unsupported person generation: %s", opts.PersonGeneration)
+	}
+	return nil
 }
 
 type imagenResponse struct {
@@ -50,6 +93,25 @@ type imagenResponse struct {
 
 // GenerateImage generates an image from a prompt
 func (c *ImagenClient) GenerateImage(prompt string) ([]byte, error) {
+	return c.GenerateImageWithOptions(context.Background(), prompt, ImageOptions{})
+}
+
+// GenerateImageWithOptions generates an image from a prompt using opts
+// The request is aborted when ctx is cancelled
+func (c *ImagenClient) GenerateImageWithOptions(ctx context.Context, prompt string, opts ImageOptions) ([]byte, error) {
+	images, err := c.GenerateImages(ctx, prompt, opts, 1)
+	if err != nil {
+		return nil, err
+	}
+	return images[0], nil
+}
+
+// GenerateImages generates up to count alternative images for the same prompt
+func (c *ImagenClient) GenerateImages(ctx context.Context, prompt string, opts ImageOptions, count int) ([][]byte, error) {
+	if count < 1 || count > MaxSampleCount {
+		return nil, fmt.Errorf("sample count must be between 1 and %d, got %d", MaxSampleCount, count)
+	}
+
 	url := fmt.Sprintf("%s?key=%s", geminiBaseURL, c.apiKey)
 
 	reqBody := imagenRequest{
@@ -57,18 +119,25 @@ func (c *ImagenClient) GenerateImage(prompt string) ([]byte, error) {
 			{Prompt: prompt},
 		},
 		Parameters: imagenParams{
-			SampleCount:      1,
+			SampleCount:      count,
 			AspectRatio:      "1:1",
 			PersonGeneration: "dont_allow",
+			NegativePrompt:   opts.NegativePrompt,
 		},
 	}
+	if opts.AspectRatio != "" {
+		reqBody.Parameters.AspectRatio = opts.AspectRatio
+	}
+	if opts.PersonGeneration != "" {
+		reqBody.Parameters.PersonGeneration = opts.PersonGeneration
+	}
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewReader(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -95,11 +164,15 @@ func (c *ImagenClient) GenerateImage(prompt string) ([]byte, error) {
 		return nil, fmt.Errorf("no image generated")
 	}
 
-	// Decode base64 image
-	imageData, err := base64.StdEncoding.DecodeString(result.Predictions[0].BytesBase64Encoded)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode image: %w", err)
+	// Decode base64 images
+	images := make([][]byte, 0, len(result.Predictions))
+	for _, prediction := range result.Predictions {
+		imageData, err := base64.StdEncoding.DecodeString(prediction.BytesBase64Encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode image: %w", err)
+		}
+		images = append(images, imageData)
 	}
 
-	return imageData, nil
+	return images, nil
 }
@@ -0,0 +1,143 @@
+package imageproc
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+)
+
+// Rendition describes one output size of a generated image
+type Rendition struct {
+	Name     string // Key in Media.ImageRenditions, e.g. "thumb"
+	MaxSize  int    // Longest edge in pixels, 0 keeps the source size
+	Format   string // "jpeg" or "png"
+	Quality  int    // JPEG quality 1-100
+	MaxBytes int    // Upper bound for the encoded file, 0 means no limit
+}
+
+// DefaultRenditions are produced for every generated card image
+var DefaultRenditions = []Rendition{
+	{Name: "thumb", MaxSize: 128, Format: "jpeg", Quality: 80, MaxBytes: 16 << 10},
+	{Name: "card", MaxSize: 512, Format: "jpeg", Quality: 85, MaxBytes: 96 << 10},
+	{Name: "full", MaxSize: 1024, Format: "jpeg", Quality: 90, MaxBytes: 256 << 10},
+}
+
+// minJPEGQuality is the lowest quality tried when fitting a size limit
+const minJPEGQuality = 40
+
+// Output is an encoded rendition ready to be stored
+type Output struct {
+	Name      string
+	Data      []byte
+	Extension string // e.g. ".jpg"
+	Width     int
+	Height    int
+}
+
+// Process decodes a source image and encodes every rendition
+func Process(data []byte, renditions []Rendition) ([]Output, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	outputs := make([]Output, 0, len(renditions))
+	for _, r := range renditions {
+		out, err := render(src, r)
+		if err != nil {
+			return nil, fmt.Errorf("rendition %s: %w", r.Name, err)
+		}
+		outputs = append(outputs, *out)
+	}
+
+	return outputs, nil
+}
+
+func render(src image.Image, r Rendition) (*Output, error) {
+	img := resize(src, r.MaxSize)
+	bounds := img.Bounds()
+
+	out := &Output{
+		Name:   r.Name,
+		Width:  bounds.Dx(),
+		Height: bounds.Dy(),
+	}
+
+	switch r.Format {
+	case "jpeg", "jpg":
+		data, err := encodeJPEG(img, r.Quality, r.MaxBytes)
+		if err != nil {
+			return nil, err
+		}
+		out.Data = data
+		out.Extension = ".jpg"
+	case "png":
+		var buf bytes.Buffer
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		if err := encoder.Encode(&buf, img); err != nil {
+			return nil, fmt.Errorf("failed to encode png: %w", err)
+		}
+		if r.MaxBytes > 0 && buf.Len() > r.MaxBytes {
+			return nil, fmt.Errorf("png is %d bytes, limit is %d", buf.Len(), r.MaxBytes)
+		}
+		out.Data = buf.Bytes()
+		out.Extension = ".png"
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", r.Format)
+	}
+
+	return out, nil
+}
+
+// encodeJPEG lowers the quality step by step until the output fits maxBytes
+func encodeJPEG(img image.Image, quality, maxBytes int) ([]byte, error) {
+	if quality <= 0 {
+		quality = jpeg.DefaultQuality
+	}
+
+	// JPEG has no alpha, flatten onto white like the generated backgrounds
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	for {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, fmt.Errorf("failed to encode jpeg: %w", err)
+		}
+
+		if maxBytes <= 0 || buf.Len() <= maxBytes {
+			return buf.Bytes(), nil
+		}
+		if quality <= minJPEGQuality {
+			return nil, fmt.Errorf("jpeg is %d bytes at quality %d, limit is %d", buf.Len(), quality, maxBytes)
+		}
+		quality = max(quality-10, minJPEGQuality)
+	}
+}
+
+// resize scales img down so its longest edge is at most maxSize
+func resize(img image.Image, maxSize int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if maxSize <= 0 || (w <= maxSize && h <= maxSize) {
+		return img
+	}
+
+	if w >= h {
+		h = max(h*maxSize/w, 1)
+		w = maxSize
+	} else {
+		w = max(w*maxSize/h, 1)
+		h = maxSize
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/example/duolingocards-backend/internal/models"
+	"github.com/example/duolingocards-backend/internal/services/events"
+)
+
+// generationJob controls a running generateMedia loop
+type generationJob struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	failures atomic.Int64 // Assets that failed so far
+
+	mu       sync.Mutex
+	resumeCh chan struct{} // non-nil while paused
+	cost     float64       // Provider cost recorded so far, USD
+}
+
+func newGenerationJob() *generationJob {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &generationJob{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+func (j *generationJob) pause() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.resumeCh == nil {
+		j.resumeCh = make(chan struct{})
+	}
+}
+
+func (j *generationJob) resume() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.resumeCh != nil {
+		close(j.resumeCh)
+		j.resumeCh = nil
+	}
+}
+
+func (j *generationJob) addCost(cost float64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.cost += cost
+}
+
+func (j *generationJob) spent() float64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.cost
+}
+
+// checkpoint blocks while the job is paused
+// Returns an error once the job has been cancelled
+func (j *generationJob) checkpoint() error {
+	j.mu.Lock()
+	resumeCh := j.resumeCh
+	j.mu.Unlock()
+
+	if resumeCh != nil {
+		select {
+		case <-resumeCh:
+		case <-j.ctx.Done():
+		}
+	}
+
+	return j.ctx.Err()
+}
+
+// CancelGeneration stops a running or paused generation job
+// In-flight provider requests are aborted; media generated so far is kept
+func (g *Generator) CancelGeneration(deckID string) (*models.GenerateStatus, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	job, status, err := g.runningJob(deckID)
+	if err != nil {
+		return nil, err
+	}
+
+	job.cancel()
+	status.Status = "cancelled"
+	return status, nil
+}
+
+// PauseGeneration stops a job after the card currently being generated
+func (g *Generator) PauseGeneration(deckID string) (*models.GenerateStatus, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	job, status, err := g.runningJob(deckID)
+	if err != nil {
+		return nil, err
+	}
+
+	job.pause()
+	status.Status = "paused"
+	g.publishJob(events.JobPaused, status)
+	return status, nil
+}
+
+// ResumeGeneration continues a paused job
+func (g *Generator) ResumeGeneration(deckID string) (*models.GenerateStatus, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	job, status, err := g.runningJob(deckID)
+	if err != nil {
+		return nil, err
+	}
+
+	job.resume()
+	status.Status = "generating"
+	g.publishJob(events.JobResumed, status)
+	return status, nil
+}
+
+// runningJob returns the active job for a deck, g.mu must be held
+func (g *Generator) runningJob(deckID string) (*generationJob, *models.GenerateStatus, error) {
+	value, ok := g.jobs.Load(deckID)
+	if !ok {
+		return nil, nil, fmt.Errorf("no running generation for deck: %s", deckID)
+	}
+
+	job := value.(*generationJob)
+	if job.ctx.Err() != nil {
+		return nil, nil, fmt.Errorf("no running generation for deck: %s", deckID)
+	}
+	return job, g.statuses[deckID], nil
+}
+
+func (g *Generator) publishJob(eventType string, status *models.GenerateStatus) {
+	g.events.Publish(events.Event{
+		Type:     eventType,
+		DeckID:   status.DeckID,
+		Status:   status.Status,
+		Progress: status.Progress,
+		Total:    status.TotalCards,
+	})
+}
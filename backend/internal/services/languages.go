@@ -0,0 +1,71 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/example/duolingocards-backend/internal/config"
+	"github.com/example/duolingocards-backend/internal/models"
+	"github.com/example/duolingocards-backend/internal/services/locale"
+)
+
+// setupLanguages merges the languages file from config into the registry
+func setupLanguages(cfg *config.Config) {
+	if cfg.LanguagesFile == "" {
+		return
+	}
+	if err := locale.LoadRegistry(cfg.LanguagesFile); err != nil {
+		log.Printf("Languages file not loaded, using built-in languages: %v", err)
+	}
+}
+
+// validateLanguages checks the deck's language tags against the registry
+// Localizations only need well-formed tags, a store may be shown in any locale
+func validateLanguages(deck *models.Deck) error {
+	if err := locale.Validate(deck.FrontLanguage); err != nil {
+		return fmt.Errorf("frontLanguage: %w", err)
+	}
+	if err := locale.Validate(deck.BackLanguage); err != nil {
+		return fmt.Errorf("backLanguage: %w", err)
+	}
+
+	for tag := range deck.Localizations {
+		if !locale.WellFormed(tag) {
+			return fmt.Errorf("localizations: invalid language tag %q", tag)
+		}
+	}
+
+	for _, card := range deck.Cards {
+		for tag := range card.Translations {
+			if err := locale.Validate(tag); err != nil {
+				return fmt.Errorf("card %s translation: %w", card.ID, err)
+			}
+		}
+		for _, example := range card.Examples {
+			for tag := range example.Translations {
+				if err := locale.Validate(tag); err != nil {
+					return fmt.Errorf("card %s example %s translation: %w", card.ID, example.ID, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// ttsVoice returns the voice for speaking lang: override when set, else the
+// language's default voice; empty lets the provider choose
+// Fails for languages no TTS provider speaks instead of synthesizing them with the wrong voice
+func ttsVoice(lang, override string) (string, error) {
+	language, ok := locale.Lookup(lang)
+	if !ok {
+		return "", fmt.Errorf("unknown language %q", lang)
+	}
+	if language.TTSProvider != locale.TTSElevenLabs {
+		return "", fmt.Errorf("no TTS provider for %s", language.Name)
+	}
+	if override != "" {
+		return override, nil
+	}
+	return language.TTSVoiceID, nil
+}
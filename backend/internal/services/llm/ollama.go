@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	ollamaDefaultBaseURL = "http://localhost:11434"
+	ollamaDefaultModel   = "llama3.1"
+)
+
+// OllamaClient talks to a local Ollama-compatible server, no credentials needed
+type OllamaClient struct {
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+func NewOllamaClient(baseURL, model string) *OllamaClient {
+	if baseURL == "" {
+		baseURL = ollamaDefaultBaseURL
+	}
+	if model == "" {
+		model = ollamaDefaultModel
+	}
+
+	return &OllamaClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		model:   model,
+		client:  &http.Client{},
+	}
+}
+
+func (c *OllamaClient) Model() string {
+	return c.model
+}
+
+// SetTransport replaces the HTTP transport, e.g. for record/replay
+func (c *OllamaClient) SetTransport(rt http.RoundTripper) {
+	c.client.Transport = rt
+}
+
+type ollamaRequest struct {
+	Model    string        `json:"model"`
+	Messages []Message     `json:"messages"`
+	Stream   bool          `json:"stream"`
+	Format   string        `json:"format,omitempty"`
+	Options  ollamaOptions `json:"options"`
+}
+
+type ollamaOptions struct {
+	Temperature float64 `json:"temperature"`
+}
+
+type ollamaResponse struct {
+	Message Message `json:"message"`
+	Error   string  `json:"error,omitempty"`
+}
+
+func (c *OllamaClient) Complete(ctx context.Context, req Request) (string, error) {
+	reqBody := ollamaRequest{
+		Model:    c.model,
+		Messages: req.Messages,
+		Options:  ollamaOptions{Temperature: req.Temperature},
+	}
+	if req.JSON {
+		reqBody.Format = "json"
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/chat", bytes.NewReader(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var completion ollamaResponse
+	if err := json.Unmarshal(body, &completion); err != nil {
+		return "", fmt.Errorf("failed to parse response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || completion.Error != "" {
+		return "", fmt.Errorf("API error (status %d): %s", resp.StatusCode, completion.Error)
+	}
+
+	return completion.Message.Content, nil
+}
@@ -0,0 +1,67 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Roles of chat messages
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Request is a single chat completion
+type Request struct {
+	Messages    []Message
+	Temperature float64
+	JSON        bool // Ask the model for a JSON object
+}
+
+// Provider completes chat requests with a language model
+type Provider interface {
+	Complete(ctx context.Context, req Request) (string, error)
+	Model() string
+	SetTransport(rt http.RoundTripper)
+}
+
+// Config selects and configures a provider
+type Config struct {
+	Provider string // "openai" or "ollama"
+	BaseURL  string // Empty uses the provider default
+	APIKey   string
+	Model    string // Empty uses the provider default
+}
+
+// New creates the provider named in cfg
+func New(cfg Config) (Provider, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "openai":
+		return NewOpenAIClient(cfg.BaseURL, cfg.APIKey, cfg.Model), nil
+	case "ollama":
+		return NewOllamaClient(cfg.BaseURL, cfg.Model), nil
+	}
+	return nil, fmt.Errorf("unknown llm provider: %s", cfg.Provider)
+}
+
+// StripCodeFence removes a markdown code fence some models wrap JSON in
+func StripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+
+	text = strings.TrimPrefix(text, "```")
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[i+1:] // language tag, e.g. ```json
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+}
@@ -0,0 +1,133 @@
+package locale
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Canonical returns tag in BCP-47 case conventions, e.g. "zh-hant-tw" -> "zh-Hant-TW"
+func Canonical(tag string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"), "-")
+	for i, part := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(part)
+		case len(part) == 4 && isAlpha(part):
+			parts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+		case len(part) == 2 && isAlpha(part):
+			parts[i] = strings.ToUpper(part)
+		default:
+			parts[i] = strings.ToLower(part)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// Base returns the primary language subtag, e.g. "pt" for "pt-BR"
+func Base(tag string) string {
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	return base
+}
+
+func isAlpha(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseAcceptLanguage returns the tags of an Accept-Language header ordered by
+// quality, e.g. "cs;q=0.8, en-US" -> ["en-US", "cs"]; "*" and q=0 are dropped
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var entries []weighted
+	for _, field := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(field), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		entries = append(entries, weighted{tag: Canonical(tag), q: q})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].q > entries[j].q
+	})
+
+	tags := make([]string, len(entries))
+	for i, entry := range entries {
+		tags[i] = entry.tag
+	}
+	return tags
+}
+
+// Fallbacks returns tag followed by its shorter prefixes,
+// e.g. "zh-Hant-TW" -> ["zh-Hant-TW", "zh-Hant", "zh"]
+func Fallbacks(tag string) []string {
+	parts := strings.Split(Canonical(tag), "-")
+	chain := make([]string, 0, len(parts))
+	for n := len(parts); n > 0; n-- {
+		chain = append(chain, strings.Join(parts[:n], "-"))
+	}
+	return chain
+}
+
+// Rank orders the available tags that fit the preferred tags, best first
+// Each preferred tag matches along its fallback chain ("en-GB" -> "en"),
+// then any other region of its language ("en" -> "en-US")
+// Available tags that fit no preferred tag are left out
+func Rank(preferred, available []string) []string {
+	var ranked []string
+	seen := make(map[string]bool)
+	add := func(tag string) {
+		if !seen[tag] {
+			seen[tag] = true
+			ranked = append(ranked, tag)
+		}
+	}
+
+	for _, want := range preferred {
+		for _, fallback := range Fallbacks(want) {
+			for _, tag := range available {
+				if strings.EqualFold(tag, fallback) {
+					add(tag)
+				}
+			}
+		}
+
+		base := Base(want)
+		for _, tag := range available {
+			if Base(tag) == base {
+				add(tag)
+			}
+		}
+	}
+	return ranked
+}
+
+// Match picks the available tag that best fits the preferred tags, see Rank
+func Match(preferred, available []string) (string, bool) {
+	if ranked := Rank(preferred, available); len(ranked) > 0 {
+		return ranked[0], true
+	}
+	return "", false
+}
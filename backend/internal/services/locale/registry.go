@@ -0,0 +1,190 @@
+package locale
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// TTS providers a language can be synthesized with
+const (
+	TTSElevenLabs = "elevenlabs"
+)
+
+// Transliterations produce the reading of non-Latin text
+const (
+	TranslitHepburn = "hepburn"              // Japanese romaji
+	TranslitRR      = "revised-romanization" // Korean
+	TranslitPinyin  = "pinyin"               // Mandarin
+)
+
+// Language describes a language cards can be written in and its defaults
+type Language struct {
+	Code            string `json:"code"`                      // BCP-47 tag, e.g. "ja" or "pt-BR"
+	Name            string `json:"name"`                      // English name
+	NativeName      string `json:"nativeName"`                // Name in the language itself
+	Script          string `json:"script"`                    // ISO 15924 code, e.g. "Latn" or "Jpan"
+	RTL             bool   `json:"rtl,omitempty"`             // Written right to left
+	TTSProvider     string `json:"ttsProvider,omitempty"`     // Empty when no provider speaks the language
+	TTSVoiceID      string `json:"ttsVoiceId,omitempty"`      // Default voice, empty uses the provider default
+	Transliteration string `json:"transliteration,omitempty"` // Reading system, empty for none
+}
+
+// languages holds the registry keyed by canonical code
+// The built-in set covers the ElevenLabs multilingual languages; a languages
+// file can add languages or override defaults such as voices
+var languages = map[string]Language{}
+
+func init() {
+	for _, language := range builtinLanguages {
+		languages[language.Code] = language
+	}
+}
+
+var builtinLanguages = []Language{
+	{Code: "ar", Name: "Arabic", NativeName: "العربية", Script: "Arab", RTL: true, TTSProvider: TTSElevenLabs},
+	{Code: "bg", Name: "Bulgarian", NativeName: "Български", Script: "Cyrl", TTSProvider: TTSElevenLabs},
+	{Code: "cs", Name: "Czech", NativeName: "Čeština", Script: "Latn", TTSProvider: TTSElevenLabs},
+	{Code: "da", Name: "Danish", NativeName: "Dansk", Script: "Latn", TTSProvider: TTSElevenLabs},
+	{Code: "de", Name: "German", NativeName: "Deutsch", Script: "Latn", TTSProvider: TTSElevenLabs},
+	{Code: "el", Name: "Greek", NativeName: "Ελληνικά", Script: "Grek", TTSProvider: TTSElevenLabs},
+	{Code: "en", Name: "English", NativeName: "English", Script: "Latn", TTSProvider: TTSElevenLabs},
+	{Code: "es", Name: "Spanish", NativeName: "Español", Script: "Latn", TTSProvider: TTSElevenLabs},
+	{Code: "fa", Name: "Persian", NativeName: "فارسی", Script: "Arab", RTL: true},
+	{Code: "fi", Name: "Finnish", NativeName: "Suomi", Script: "Latn", TTSProvider: TTSElevenLabs},
+	{Code: "fil", Name: "Filipino", NativeName: "Filipino", Script: "Latn", TTSProvider: TTSElevenLabs},
+	{Code: "fr", Name: "French", NativeName: "Français", Script: "Latn", TTSProvider: TTSElevenLabs},
+	{Code: "he", Name: "Hebrew", NativeName: "עברית", Script: "Hebr", RTL: true},
+	{Code: "hi", Name: "Hindi", NativeName: "हिन्दी", Script: "Deva", TTSProvider: TTSElevenLabs},
+	{Code: "hr", Name: "Croatian", NativeName: "Hrvatski", Script: "Latn", TTSProvider: TTSElevenLabs},
+	{Code: "id", Name: "Indonesian", NativeName: "Bahasa Indonesia", Script: "Latn", TTSProvider: TTSElevenLabs},
+	{Code: "it", Name: "Italian", NativeName: "Italiano", Script: "Latn", TTSProvider: TTSElevenLabs},
+	{Code: "ja", Name: "Japanese", NativeName: "日本語", Script: "Jpan", TTSProvider: TTSElevenLabs, Transliteration: TranslitHepburn},
+	{Code: "ko", Name: "Korean", NativeName: "한국어", Script: "Kore", TTSProvider: TTSElevenLabs, Transliteration: TranslitRR},
+	{Code: "ms", Name: "Malay", NativeName: "Bahasa Melayu", Script: "Latn", TTSProvider: TTSElevenLabs},
+	{Code: "nl", Name: "Dutch", NativeName: "Nederlands", Script: "Latn", TTSProvider: TTSElevenLabs},
+	{Code: "pl", Name: "Polish", NativeName: "Polski", Script: "Latn", TTSProvider: TTSElevenLabs},
+	{Code: "pt", Name: "Portuguese", NativeName: "Português", Script: "Latn", TTSProvider: TTSElevenLabs},
+	{Code: "ro", Name: "Romanian", NativeName: "Română", Script: "Latn", TTSProvider: TTSElevenLabs},
+	{Code: "ru", Name: "Russian", NativeName: "Русский", Script: "Cyrl", TTSProvider: TTSElevenLabs},
+	{Code: "sk", Name: "Slovak", NativeName: "Slovenčina", Script: "Latn", TTSProvider: TTSElevenLabs},
+	{Code: "sv", Name: "Swedish", NativeName: "Svenska", Script: "Latn", TTSProvider: TTSElevenLabs},
+	{Code: "ta", Name: "Tamil", NativeName: "தமிழ்", Script: "Taml", TTSProvider: TTSElevenLabs},
+	{Code: "tr", Name: "Turkish", NativeName: "Türkçe", Script: "Latn", TTSProvider: TTSElevenLabs},
+	{Code: "uk", Name: "Ukrainian", NativeName: "Українська", Script: "Cyrl", TTSProvider: TTSElevenLabs},
+	{Code: "zh", Name: "Chinese", NativeName: "中文", Script: "Hans", TTSProvider: TTSElevenLabs, Transliteration: TranslitPinyin},
+}
+
+// LoadRegistry merges languages from a JSON file (an array of Language) into
+// the registry; entries replace built-in languages with the same code
+func LoadRegistry(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var entries []Language
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("invalid languages file: %w", err)
+	}
+
+	for _, language := range entries {
+		if !WellFormed(language.Code) {
+			return fmt.Errorf("invalid language tag %q in %s", language.Code, path)
+		}
+		if language.TTSProvider != "" && language.TTSProvider != TTSElevenLabs {
+			return fmt.Errorf("unknown TTS provider %q for %s", language.TTSProvider, language.Code)
+		}
+		switch language.Transliteration {
+		case "", TranslitHepburn, TranslitRR, TranslitPinyin:
+		default:
+			return fmt.Errorf("unknown transliteration %q for %s", language.Transliteration, language.Code)
+		}
+		language.Code = Canonical(language.Code)
+		languages[language.Code] = language
+	}
+	return nil
+}
+
+// Languages returns the registry sorted by code
+func Languages() []Language {
+	list := make([]Language, 0, len(languages))
+	for _, language := range languages {
+		list = append(list, language)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Code < list[j].Code
+	})
+	return list
+}
+
+// Lookup returns the registry entry for tag along its fallback chain,
+// e.g. "pt-BR" uses "pt" unless Brazilian Portuguese is registered
+func Lookup(tag string) (Language, bool) {
+	for _, fallback := range Fallbacks(tag) {
+		if language, ok := languages[fallback]; ok {
+			return language, true
+		}
+	}
+	return Language{}, false
+}
+
+// Validate checks that tag is well-formed BCP-47 and its language is registered
+func Validate(tag string) error {
+	if strings.TrimSpace(tag) == "" {
+		return fmt.Errorf("language required")
+	}
+	if !WellFormed(tag) {
+		return fmt.Errorf("invalid language tag %q", tag)
+	}
+	if _, ok := Lookup(tag); !ok {
+		return fmt.Errorf("unknown language %q", tag)
+	}
+	return nil
+}
+
+// WellFormed reports whether tag follows the BCP-47 language-script-region-variant
+// syntax, e.g. "ja", "zh-Hant-TW" or "sl-rozaj"; extensions and private use are not supported
+func WellFormed(tag string) bool {
+	parts := strings.Split(tag, "-")
+	if len(parts[0]) < 2 || len(parts[0]) > 3 || !isAlpha(parts[0]) {
+		return false
+	}
+
+	// Subtags must come in order: script, region, then variants
+	stage := 0
+	for _, part := range parts[1:] {
+		switch {
+		case stage < 1 && len(part) == 4 && isAlpha(part):
+			stage = 1
+		case stage < 2 && (len(part) == 2 && isAlpha(part) || len(part) == 3 && isDigits(part)):
+			stage = 2
+		case isVariant(part):
+			stage = 3
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isVariant matches 5-8 alphanumerics, or 4 starting with a digit
+func isVariant(s string) bool {
+	for _, r := range strings.ToLower(s) {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return len(s) >= 5 && len(s) <= 8 || len(s) == 4 && s[0] >= '0' && s[0] <= '9'
+}
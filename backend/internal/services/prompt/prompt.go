@@ -0,0 +1,187 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/example/duolingocards-backend/internal/models"
+)
+
+// maxPromptLength keeps rendered prompts within what the image provider accepts
+const maxPromptLength = 2000
+
+// Legacy placeholders from the original string-replacement templates
+var legacyPlaceholders = strings.NewReplacer(
+	"{word}", "{{.Word}}",
+	"{front}", "{{.Front}}",
+	"{back}", "{{.Back}}",
+	"{reading}", "{{.Reading}}",
+)
+
+// Only side-effect free helpers are exposed to templates
+var funcs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"join":  strings.Join,
+	"has": func(list []string, value string) bool {
+		for _, v := range list {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	},
+	"default": func(fallback, value string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+// Data is the value templates are executed against
+type Data struct {
+	ID            string
+	Word          string // The translation (backText), what the image should depict
+	Front         string
+	Back          string
+	Reading       string
+	Tags          []string
+	FrontLanguage string
+	BackLanguage  string
+}
+
+// NewData builds template data for a card of deck
+func NewData(deck *models.Deck, card *models.Card) Data {
+	return Data{
+		ID:            card.ID,
+		Word:          card.BackText,
+		Front:         card.FrontText,
+		Back:          card.BackText,
+		Reading:       card.Reading,
+		Tags:          card.Tags,
+		FrontLanguage: deck.FrontLanguage,
+		BackLanguage:  deck.BackLanguage,
+	}
+}
+
+// Parse compiles a prompt template
+// Legacy {word}, {front}, {back} and {reading} placeholders are still accepted
+func Parse(text string) (*template.Template, error) {
+	tmpl, err := template.New("prompt").
+		Funcs(funcs).
+		Option("missingkey=error").
+		Parse(legacyPlaceholders.Replace(text))
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt template: %w", err)
+	}
+
+	if len(tmpl.Templates()) > 1 {
+		return nil, fmt.Errorf("invalid prompt template: define and block are not allowed")
+	}
+	if err := checkLoops(tmpl.Tree.Root, false); err != nil {
+		return nil, fmt.Errorf("invalid prompt template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// checkLoops only allows range over a data field such as .Tags, without nesting,
+// and no template calls, which could recurse
+// Either could otherwise run for a long time without writing any output
+func checkLoops(node parse.Node, inRange bool) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkLoops(child, inRange); err != nil {
+				return err
+			}
+		}
+	case *parse.RangeNode:
+		if inRange {
+			return fmt.Errorf("nested range is not allowed")
+		}
+		cmds := n.Pipe.Cmds
+		if len(cmds) != 1 || len(cmds[0].Args) != 1 {
+			return fmt.Errorf("range must be over a field such as .Tags")
+		}
+		if _, ok := cmds[0].Args[0].(*parse.FieldNode); !ok {
+			return fmt.Errorf("range must be over a field such as .Tags")
+		}
+		if err := checkLoops(n.List, true); err != nil {
+			return err
+		}
+		return checkLoops(n.ElseList, inRange)
+	case *parse.TemplateNode:
+		return fmt.Errorf("template calls are not allowed")
+	case *parse.IfNode:
+		if err := checkLoops(n.List, inRange); err != nil {
+			return err
+		}
+		return checkLoops(n.ElseList, inRange)
+	case *parse.WithNode:
+		if err := checkLoops(n.List, inRange); err != nil {
+			return err
+		}
+		return checkLoops(n.ElseList, inRange)
+	}
+	return nil
+}
+
+// errPromptTooLong stops template execution once the output exceeds maxPromptLength
+var errPromptTooLong = errors.New("prompt too long")
+
+// limitedWriter fails writes past maxPromptLength so rendering aborts early
+type limitedWriter struct {
+	strings.Builder
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.Len()+len(p) > maxPromptLength {
+		return 0, errPromptTooLong
+	}
+	return w.Builder.Write(p)
+}
+
+// Render executes a prompt template against data
+func Render(text string, data Data) (string, error) {
+	tmpl, err := Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var out limitedWriter
+	if err := tmpl.Execute(&out, data); err != nil {
+		if errors.Is(err, errPromptTooLong) {
+			return "", fmt.Errorf("rendered prompt exceeds %d characters", maxPromptLength)
+		}
+		return "", fmt.Errorf("failed to render prompt: %w", err)
+	}
+
+	rendered := strings.Join(strings.Fields(out.String()), " ")
+	if len(rendered) > maxPromptLength {
+		return "", fmt.Errorf("rendered prompt is %d characters, limit is %d", len(rendered), maxPromptLength)
+	}
+
+	return rendered, nil
+}
+
+// Validate checks that a template parses and renders against sample data
+func Validate(text string) error {
+	_, err := Render(text, Data{
+		ID:            "sample",
+		Word:          "sample",
+		Front:         "sample",
+		Back:          "sample",
+		Reading:       "sample",
+		Tags:          []string{"sample"},
+		FrontLanguage: "ja",
+		BackLanguage:  "cs",
+	})
+	return err
+}
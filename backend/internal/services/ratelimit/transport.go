@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultRetryAfter is used when a 429 response carries no usable Retry-After header
+const defaultRetryAfter = time.Second
+
+// Transport is an http.RoundTripper that applies a Limiter to every request
+// and retries 429 responses after the delay the provider asks for
+type Transport struct {
+	Base       http.RoundTripper // nil means http.DefaultTransport
+	Limiter    *Limiter
+	MaxRetries int
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	for attempt := 0; ; attempt++ {
+		outReq := req
+		if attempt > 0 {
+			outReq = req.Clone(req.Context())
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				outReq.Body = body
+			}
+		}
+
+		release, err := t.Limiter.Acquire(req.Context())
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := base.RoundTrip(outReq)
+		if err != nil {
+			release()
+			return nil, err
+		}
+
+		retryable := req.Body == nil || req.GetBody != nil
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= t.MaxRetries || !retryable {
+			// Hold the concurrency slot until the caller has read the body
+			resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+			return resp, nil
+		}
+
+		delay := retryAfter(resp.Header.Get("Retry-After"), defaultRetryAfter<<attempt)
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		release()
+
+		t.Limiter.Backoff(delay)
+	}
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(header string, fallback time.Duration) time.Duration {
+	if header == "" {
+		return fallback
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+		return 0
+	}
+	return fallback
+}
+
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
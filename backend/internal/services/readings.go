@@ -0,0 +1,176 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/example/duolingocards-backend/internal/config"
+	"github.com/example/duolingocards-backend/internal/models"
+	"github.com/example/duolingocards-backend/internal/services/locale"
+	"github.com/example/duolingocards-backend/internal/services/translit"
+)
+
+// Reading problems reported by ValidateReadings
+const (
+	readingMissing     = "missing"     // No reading, one could be computed
+	readingMismatch    = "mismatch"    // Reading differs from the computed one
+	readingUnavailable = "unavailable" // No reading could be computed, e.g. unknown kanji
+)
+
+// setupTransliteration registers the dictionary based romanizers from config
+// Without a dictionary Japanese is limited to kana and Chinese is not romanized
+func setupTransliteration(cfg *config.Config) {
+	if cfg.TranslitJapaneseDict != "" {
+		dict, err := translit.LoadDictionary(cfg.TranslitJapaneseDict)
+		if err != nil {
+			log.Printf("Japanese dictionary not loaded, kanji readings disabled: %v", err)
+		} else {
+			translit.Register("ja", &translit.Japanese{Analyzer: dict})
+		}
+	}
+
+	if cfg.TranslitChineseDict != "" {
+		dict, err := translit.LoadDictionary(cfg.TranslitChineseDict)
+		if err != nil {
+			log.Printf("Chinese dictionary not loaded, pinyin disabled: %v", err)
+		} else {
+			translit.Register("zh", &translit.Chinese{Dictionary: dict})
+		}
+	}
+}
+
+// computeReading romanizes text in lang
+// Returns "" without error when the text needs no reading, the registry names
+// no transliteration for the language or it has no romanizer
+func computeReading(lang, text string) (string, error) {
+	if !translit.NeedsReading(text) {
+		return "", nil
+	}
+	if language, ok := locale.Lookup(lang); !ok || language.Transliteration == "" {
+		return "", nil
+	}
+	romanizer, ok := translit.For(lang)
+	if !ok {
+		return "", nil
+	}
+	return romanizer.Romanize(text)
+}
+
+// fillReadings sets missing card readings from the front text and returns how many were set
+// Cards whose reading cannot be computed are left for an editor
+func fillReadings(deck *models.Deck) int {
+	filled := 0
+	for i := range deck.Cards {
+		card := &deck.Cards[i]
+		if card.Reading != "" {
+			continue
+		}
+
+		reading, err := computeReading(deck.FrontLanguage, card.FrontText)
+		if err != nil || reading == "" {
+			continue
+		}
+		card.Reading = reading
+		filled++
+	}
+	return filled
+}
+
+// annotateFurigana recomputes ruby segments for cards in languages with an
+// annotator (Japanese) and returns how many cards changed
+// Cards whose reading does not fit their text lose stale segments
+func annotateFurigana(deck *models.Deck) int {
+	romanizer, ok := translit.For(deck.FrontLanguage)
+	if !ok {
+		return 0
+	}
+	annotator, ok := romanizer.(translit.Annotator)
+	if !ok {
+		return 0
+	}
+
+	changed := 0
+	for i := range deck.Cards {
+		card := &deck.Cards[i]
+
+		var furigana []models.RubySegment
+		segments, err := annotator.Furigana(card.FrontText, card.Reading)
+		if err == nil {
+			for _, segment := range segments {
+				furigana = append(furigana, models.RubySegment{Base: segment.Base, Ruby: segment.Ruby})
+			}
+		}
+
+		if !slices.Equal(card.Furigana, furigana) {
+			card.Furigana = furigana
+			changed++
+		}
+	}
+	return changed
+}
+
+// speechReading returns the form of a reading that TTS should speak
+// Japanese romaji readings are spoken from kana, voices read romaji as English;
+// a reading that is not romaji falls back to the text itself
+func speechReading(lang, text, reading string) string {
+	if locale.Base(lang) != "ja" {
+		return reading
+	}
+	for _, r := range reading {
+		if translit.IsKana(r) {
+			return reading
+		}
+	}
+
+	kana, err := translit.RomajiToHiragana(reading)
+	if err != nil {
+		return text
+	}
+	return kana
+}
+
+// ValidateReadings compares card readings with computed ones
+// Readings match when they differ only in spelling conventions such as macrons
+func (g *Generator) ValidateReadings(deckID string) ([]models.ReadingIssue, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	deck, ok := g.decks[deckID]
+	if !ok {
+		return nil, fmt.Errorf("deck not found: %s", deckID)
+	}
+
+	issues := []models.ReadingIssue{}
+	for _, card := range deck.Cards {
+		issue := models.ReadingIssue{
+			CardID:    card.ID,
+			FrontText: card.FrontText,
+			Reading:   card.Reading,
+		}
+
+		computed, err := computeReading(deck.FrontLanguage, card.FrontText)
+		switch {
+		case errors.Is(err, translit.ErrNoReading):
+			issue.Problem = readingUnavailable
+			issue.Detail = err.Error()
+		case err != nil:
+			return nil, err
+		case computed == "":
+			continue
+		case card.Reading == "":
+			issue.Problem = readingMissing
+			issue.Computed = computed
+		case !translit.Matches(card.Reading, computed):
+			issue.Problem = readingMismatch
+			issue.Computed = computed
+		default:
+			continue
+		}
+
+		issues = append(issues, issue)
+	}
+
+	return issues, nil
+}
@@ -0,0 +1,200 @@
+package recorder
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Modes for provider HTTP clients
+const (
+	ModeLive   = "live"   // Talk to the provider, nothing is stored
+	ModeRecord = "record" // Talk to the provider and store every exchange
+	ModeReplay = "replay" // Serve stored exchanges, never touch the network
+)
+
+// Redaction lists request parts that hold credentials
+// They are dropped before a request is matched or written to disk, so
+// recordings made with real keys replay on machines without them
+type Redaction struct {
+	QueryParams []string // e.g. "key"
+	JSONFields  []string // Top-level fields of JSON request bodies, e.g. "password"
+}
+
+// Transport is an http.RoundTripper that records or replays exchanges
+// Each exchange is stored as one JSON file named after the request it matches
+type Transport struct {
+	Base   http.RoundTripper // Used in record mode, nil means http.DefaultTransport
+	Mode   string
+	Dir    string
+	Redact Redaction
+}
+
+// Wrap returns base unchanged in live mode and a recording transport otherwise
+func Wrap(base http.RoundTripper, mode, dir string, redact Redaction) (http.RoundTripper, error) {
+	switch mode {
+	case "", ModeLive:
+		return base, nil
+	case ModeRecord, ModeReplay:
+		return &Transport{Base: base, Mode: mode, Dir: dir, Redact: redact}, nil
+	}
+	return nil, fmt.Errorf("unknown http mode: %s (want %s, %s or %s)", mode, ModeLive, ModeRecord, ModeReplay)
+}
+
+// exchange is the on-disk form of one request and its response
+type exchange struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+type recordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+type recordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"` // base64 in JSON
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := t.normalize(req)
+	if err != nil {
+		return nil, err
+	}
+	file := filepath.Join(t.Dir, recorded.filename())
+
+	if t.Mode == ModeReplay {
+		return t.replay(req, recorded, file)
+	}
+	return t.record(req, recorded, file)
+}
+
+func (t *Transport) replay(req *http.Request, recorded *recordedRequest, file string) (*http.Response, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		log.Printf("Recorder: no recording for %s %s in %s", recorded.Method, recorded.URL, t.Dir)
+		return nil, fmt.Errorf("recorder: no recording for %s %s (expected %s); record it with the client in %s mode", recorded.Method, recorded.URL, file, ModeRecord)
+	}
+
+	var ex exchange
+	if err := json.Unmarshal(data, &ex); err != nil {
+		return nil, fmt.Errorf("recorder: invalid recording %s: %w", file, err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", ex.Response.StatusCode, http.StatusText(ex.Response.StatusCode)),
+		StatusCode:    ex.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        ex.Response.Header,
+		Body:          io.NopCloser(bytes.NewReader(ex.Response.Body)),
+		ContentLength: int64(len(ex.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (t *Transport) record(req *http.Request, recorded *recordedRequest, file string) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	data, err := json.MarshalIndent(exchange{
+		Request: *recorded,
+		Response: recordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Body:       body,
+		},
+	}, "", "  ")
+	if err != nil {
+		return resp, nil
+	}
+
+	if err := os.MkdirAll(t.Dir, 0755); err != nil {
+		log.Printf("Recorder: failed to create %s: %v", t.Dir, err)
+		return resp, nil
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		log.Printf("Recorder: failed to write %s: %v", file, err)
+	}
+
+	return resp, nil
+}
+
+// normalize reads the request body (restoring it for the real request)
+// and strips credentials listed in t.Redact
+func (t *Transport) normalize(req *http.Request) (*recordedRequest, error) {
+	u := *req.URL
+	if len(t.Redact.QueryParams) > 0 {
+		query := u.Query()
+		for _, param := range t.Redact.QueryParams {
+			query.Del(param)
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("recorder: read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if len(t.Redact.JSONFields) > 0 && len(body) > 0 {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(body, &fields); err == nil {
+			for _, field := range t.Redact.JSONFields {
+				delete(fields, field)
+			}
+			// Map keys marshal sorted, so field order no longer matters
+			body, _ = json.Marshal(fields)
+		}
+	}
+
+	return &recordedRequest{
+		Method: req.Method,
+		URL:    redactedURL(&u),
+		Body:   string(body),
+	}, nil
+}
+
+// filename derives a stable file name from the normalized request
+func (r *recordedRequest) filename() string {
+	sum := sha256.Sum256([]byte(r.Method + "\n" + r.URL + "\n" + r.Body))
+	return strings.ToLower(r.Method) + "-" + hex.EncodeToString(sum[:8]) + ".json"
+}
+
+func redactedURL(u *url.URL) string {
+	u.User = nil
+	return u.String()
+}
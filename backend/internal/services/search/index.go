@@ -0,0 +1,277 @@
+package search
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Searchable card fields, in ranking order
+const (
+	FieldFront   = "frontText"
+	FieldBack    = "backText" // Includes translations
+	FieldReading = "reading"
+)
+
+var fieldOrder = []string{FieldFront, FieldBack, FieldReading}
+
+// Document is one card to index
+type Document struct {
+	DeckID string
+	CardID string
+	Fields map[string][]string // Field name -> texts
+}
+
+// Hit is a card matching a query
+type Hit struct {
+	DeckID  string
+	CardID  string
+	Matched []string // Fields containing the query, in ranking order
+	Exact   bool     // A whole field text equals the query
+	order   int      // Position of the card in its deck
+}
+
+// Index is an in-memory inverted index of cards
+// Words match by prefix, Han and kana runs by character n-grams
+type Index struct {
+	decks    map[string][]*document
+	postings map[string]map[*document]struct{}
+	terms    []string // Sorted keys of postings for prefix lookups, rebuilt when keys change
+	mu       sync.RWMutex
+}
+
+type document struct {
+	deckID string
+	cardID string
+	order  int
+	fields map[string]*field
+	terms  []string
+}
+
+type field struct {
+	texts []string            // Normalized
+	words map[string]struct{} // Word tokens
+}
+
+func NewIndex() *Index {
+	return &Index{
+		decks:    make(map[string][]*document),
+		postings: make(map[string]map[*document]struct{}),
+	}
+}
+
+// Update replaces the indexed cards of a deck
+func (idx *Index) Update(deckID string, docs []Document) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(deckID)
+
+	indexed := make([]*document, 0, len(docs))
+	for i, doc := range docs {
+		d := &document{deckID: deckID, cardID: doc.CardID, order: i, fields: make(map[string]*field)}
+		terms := make(map[string]struct{})
+
+		for name, texts := range doc.Fields {
+			f := &field{words: make(map[string]struct{})}
+			for _, text := range texts {
+				text = Normalize(text)
+				if strings.TrimSpace(text) == "" {
+					continue
+				}
+				f.texts = append(f.texts, text)
+
+				words, runs := tokens(text)
+				for _, word := range words {
+					f.words[word] = struct{}{}
+					terms[word] = struct{}{}
+				}
+				for _, run := range runs {
+					for _, gram := range grams(run) {
+						terms[gram] = struct{}{}
+					}
+				}
+			}
+			d.fields[name] = f
+		}
+
+		for term := range terms {
+			d.terms = append(d.terms, term)
+			if idx.postings[term] == nil {
+				idx.postings[term] = make(map[*document]struct{})
+				idx.terms = nil
+			}
+			idx.postings[term][d] = struct{}{}
+		}
+		indexed = append(indexed, d)
+	}
+
+	idx.decks[deckID] = indexed
+	idx.sortTerms()
+}
+
+// Remove drops a deck from the index
+func (idx *Index) Remove(deckID string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(deckID)
+	idx.sortTerms()
+}
+
+// sortTerms rebuilds the sorted terms after postings gained or lost keys, idx.mu must be held
+func (idx *Index) sortTerms() {
+	if idx.terms != nil {
+		return
+	}
+	idx.terms = make([]string, 0, len(idx.postings))
+	for term := range idx.postings {
+		idx.terms = append(idx.terms, term)
+	}
+	sort.Strings(idx.terms)
+}
+
+func (idx *Index) remove(deckID string) {
+	for _, d := range idx.decks[deckID] {
+		for _, term := range d.terms {
+			delete(idx.postings[term], d)
+			if len(idx.postings[term]) == 0 {
+				delete(idx.postings, term)
+				idx.terms = nil
+			}
+		}
+	}
+	delete(idx.decks, deckID)
+}
+
+// Search returns the cards containing every word and run of the query,
+// exact matches first, then by matched field, deck and card order
+func (idx *Index) Search(query string) []Hit {
+	words, runs := tokens(Normalize(query))
+	if len(words) == 0 && len(runs) == 0 {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var candidates map[*document]struct{}
+	first := true
+	narrow := func(docs map[*document]struct{}) {
+		if first {
+			candidates = docs
+			first = false
+			return
+		}
+		next := make(map[*document]struct{})
+		for d := range candidates {
+			if _, ok := docs[d]; ok {
+				next[d] = struct{}{}
+			}
+		}
+		candidates = next
+	}
+
+	for _, word := range words {
+		narrow(idx.wordPostings(word))
+	}
+	for _, run := range runs {
+		for _, gram := range queryGrams(run) {
+			narrow(idx.postings[gram])
+		}
+	}
+
+	normalized := strings.Join(strings.Fields(Normalize(query)), " ")
+	var hits []Hit
+	for d := range candidates {
+		hit := Hit{DeckID: d.deckID, CardID: d.cardID, order: d.order}
+		phrase := true
+		for _, run := range runs {
+			phrase = phrase && d.containsRun(run)
+		}
+		if !phrase {
+			continue
+		}
+
+		for _, name := range fieldOrder {
+			f, ok := d.fields[name]
+			if !ok || !f.matches(words, runs) {
+				continue
+			}
+			hit.Matched = append(hit.Matched, name)
+			for _, text := range f.texts {
+				hit.Exact = hit.Exact || strings.Join(strings.Fields(text), " ") == normalized
+			}
+		}
+		hits = append(hits, hit)
+	}
+
+	slices.SortFunc(hits, func(a, b Hit) int {
+		if a.Exact != b.Exact {
+			if a.Exact {
+				return -1
+			}
+			return 1
+		}
+		if c := cmp.Compare(slices.Index(fieldOrder, a.Matched[0]), slices.Index(fieldOrder, b.Matched[0])); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.DeckID, b.DeckID); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.order, b.order)
+	})
+	return hits
+}
+
+// wordPostings returns the documents with a word starting with word
+// Single characters match whole words only
+func (idx *Index) wordPostings(word string) map[*document]struct{} {
+	if utf8.RuneCountInString(word) < 2 {
+		return idx.postings[word]
+	}
+
+	docs := make(map[*document]struct{})
+	start := sort.SearchStrings(idx.terms, word)
+	for _, term := range idx.terms[start:] {
+		if !strings.HasPrefix(term, word) {
+			break
+		}
+		for d := range idx.postings[term] {
+			docs[d] = struct{}{}
+		}
+	}
+	return docs
+}
+
+func (d *document) containsRun(run string) bool {
+	for _, f := range d.fields {
+		for _, text := range f.texts {
+			if strings.Contains(text, run) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matches reports whether the field contains any of the query's words or runs
+func (f *field) matches(words, runs []string) bool {
+	for _, word := range words {
+		for w := range f.words {
+			if w == word || utf8.RuneCountInString(word) >= 2 && strings.HasPrefix(w, word) {
+				return true
+			}
+		}
+	}
+	for _, run := range runs {
+		for _, text := range f.texts {
+			if strings.Contains(text, run) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/example/duolingocards-backend/internal/services/translit"
+)
+
+// Normalize folds text for matching: full-width Latin and half-width kana
+// to their usual width, katakana to hiragana, case, and diacritics
+// ("Děkuji" and "dekuji", "ｱﾘｶﾞﾄｳ" and "ありがとう" normalize alike)
+func Normalize(text string) string {
+	text = translit.ToHiragana(foldWidth(text))
+	if folded, _, err := transform.String(stripMarks, text); err == nil {
+		text = folded
+	}
+	return diacritics.Replace(strings.ToLower(text))
+}
+
+// stripMarks decomposes text and drops combining accents, so precomposed and
+// decomposed spellings fold alike; kana voicing marks are kept and recomposed
+var stripMarks = transform.Chain(norm.NFD, runes.Remove(runes.Predicate(isAccent)), norm.NFC)
+
+func isAccent(r rune) bool {
+	return unicode.Is(unicode.Mn, r) && r != '\u3099' && r != '\u309A'
+}
+
+// halfwidthKana maps U+FF66-U+FF9D to full-width katakana
+var halfwidthKana = []rune("ヲァィゥェォャュョッーアイウエオカキクケコサシスセソタチツテトナニヌネノハヒフヘホマミムメモヤユヨラリルレロワン")
+
+const (
+	halfwidthFirst      = 0xFF66
+	halfwidthDakuten    = 0xFF9E
+	halfwidthHandakuten = 0xFF9F
+)
+
+// foldWidth converts full-width ASCII and the ideographic space to ASCII
+// and half-width katakana, including separate voicing marks, to full width
+func foldWidth(text string) string {
+	out := make([]rune, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r >= 0xFF01 && r <= 0xFF5E:
+			out = append(out, r-0xFEE0)
+		case r == 0x3000:
+			out = append(out, ' ')
+		case r >= halfwidthFirst && r < halfwidthFirst+rune(len(halfwidthKana)):
+			out = append(out, halfwidthKana[r-halfwidthFirst])
+		case r == halfwidthDakuten || r == halfwidthHandakuten:
+			if n := len(out); n > 0 {
+				if voiced, ok := voice(out[n-1], r == halfwidthHandakuten); ok {
+					out[n-1] = voiced
+					continue
+				}
+			}
+			out = append(out, r)
+		default:
+			out = append(out, r)
+		}
+	}
+	return string(out)
+}
+
+// voice adds a dakuten (or handakuten) to a katakana, e.g. カ -> ガ, ハ -> パ
+func voice(r rune, handakuten bool) (rune, bool) {
+	switch {
+	case handakuten:
+		if strings.ContainsRune("ハヒフヘホ", r) {
+			return r + 2, true
+		}
+	case r == 'ウ':
+		return 'ヴ', true
+	case strings.ContainsRune("カキクケコサシスセソタチツテトハヒフヘホ", r):
+		return r + 1, true
+	}
+	return r, false
+}
+
+// diacritics folds lower-case letters that have no decomposition to strip
+var diacritics = strings.NewReplacer(foldPairs()...)
+
+func foldPairs() []string {
+	table := map[string]string{
+		"d": "đ", "h": "ħ", "i": "ı", "l": "ŀł", "n": "ŉ", "o": "ø", "t": "ŧ",
+		"ss": "ß", "ae": "æ", "oe": "œ",
+	}
+
+	var pairs []string
+	for plain, accented := range table {
+		for _, r := range accented {
+			pairs = append(pairs, string(r), plain)
+		}
+	}
+	return pairs
+}
+
+// isGramRune reports whether r belongs to a script written without spaces,
+// which is indexed by character n-grams instead of words
+func isGramRune(r rune) bool {
+	return unicode.Is(unicode.Han, r) || translit.IsKana(r)
+}
+
+// tokens splits normalized text into words and runs of gram runes
+func tokens(text string) (words, runs []string) {
+	var current []rune
+	gram := false
+	flush := func() {
+		if len(current) > 0 {
+			if gram {
+				runs = append(runs, string(current))
+			} else {
+				words = append(words, string(current))
+			}
+		}
+		current = current[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isGramRune(r):
+			if !gram {
+				flush()
+				gram = true
+			}
+			current = append(current, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r):
+			if gram {
+				flush()
+				gram = false
+			}
+			current = append(current, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words, runs
+}
+
+// grams returns the single characters and bigrams of a run
+func grams(run string) []string {
+	runes := []rune(run)
+	out := make([]string, 0, 2*len(runes))
+	for i := range runes {
+		out = append(out, string(runes[i]))
+		if i+1 < len(runes) {
+			out = append(out, string(runes[i:i+2]))
+		}
+	}
+	return out
+}
+
+// queryGrams returns the grams a run must have: its bigrams, or the character itself
+func queryGrams(run string) []string {
+	runes := []rune(run)
+	if len(runes) == 1 {
+		return []string{run}
+	}
+	out := make([]string, 0, len(runes)-1)
+	for i := 0; i+1 < len(runes); i++ {
+		out = append(out, string(runes[i:i+2]))
+	}
+	return out
+}
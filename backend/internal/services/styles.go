@@ -0,0 +1,172 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/example/duolingocards-backend/internal/models"
+	"github.com/example/duolingocards-backend/internal/services/image"
+	"github.com/example/duolingocards-backend/internal/services/prompt"
+)
+
+var (
+	// ErrStyleNotFound is returned for an unknown style ID
+	ErrStyleNotFound = errors.New("style not found")
+	// ErrStyleExists is returned when creating a style whose ID is taken
+	ErrStyleExists = errors.New("style already exists")
+)
+
+// StyleStore keeps art style presets as JSON files in a directory
+type StyleStore struct {
+	path   string
+	styles map[string]*models.StylePreset
+	mu     sync.RWMutex
+}
+
+func NewStyleStore(path string) *StyleStore {
+	s := &StyleStore{
+		path:   path,
+		styles: make(map[string]*models.StylePreset),
+	}
+	s.load()
+	return s
+}
+
+func (s *StyleStore) load() {
+	entries, err := os.ReadDir(s.path)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.path, entry.Name()))
+		if err != nil {
+			continue
+		}
+
+		var style models.StylePreset
+		if err := json.Unmarshal(data, &style); err != nil {
+			continue
+		}
+
+		s.styles[style.ID] = &style
+	}
+}
+
+// List returns all presets sorted by ID
+func (s *StyleStore) List() []models.StylePreset {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	styles := make([]models.StylePreset, 0, len(s.styles))
+	for _, style := range s.styles {
+		styles = append(styles, *style)
+	}
+	sort.Slice(styles, func(i, j int) bool { return styles[i].ID < styles[j].ID })
+
+	return styles
+}
+
+func (s *StyleStore) Get(id string) (*models.StylePreset, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	style, ok := s.styles[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrStyleNotFound, id)
+	}
+
+	preset := *style
+	return &preset, nil
+}
+
+// Create validates and stores a new preset, failing if the ID is taken
+func (s *StyleStore) Create(style *models.StylePreset) error {
+	return s.store(style, false)
+}
+
+// Save validates and stores a preset, replacing any preset with the same ID
+func (s *StyleStore) Save(style *models.StylePreset) error {
+	return s.store(style, true)
+}
+
+func (s *StyleStore) store(style *models.StylePreset, replace bool) error {
+	if err := validateStyle(style); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(style, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.styles[style.ID]; ok && !replace {
+		return fmt.Errorf("%w: %s", ErrStyleExists, style.ID)
+	}
+
+	if err := os.MkdirAll(s.path, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(s.path, style.ID+".json"), data, 0644); err != nil {
+		return err
+	}
+
+	preset := *style
+	s.styles[style.ID] = &preset
+	return nil
+}
+
+func (s *StyleStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.styles[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrStyleNotFound, id)
+	}
+
+	if err := os.Remove(filepath.Join(s.path, id+".json")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	delete(s.styles, id)
+	return nil
+}
+
+func validateStyle(style *models.StylePreset) error {
+	if style.ID == "" {
+		return fmt.Errorf("style id required")
+	}
+	if filepath.Base(style.ID) != style.ID || style.ID == "." || style.ID == ".." {
+		return fmt.Errorf("invalid style id: %s", style.ID)
+	}
+
+	for name, text := range map[string]string{
+		"promptPrefix":   style.PromptPrefix,
+		"promptSuffix":   style.PromptSuffix,
+		"negativePrompt": style.NegativePrompt,
+	} {
+		if text == "" {
+			continue
+		}
+		if err := prompt.Validate(text); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return image.ValidateOptions(image.ImageOptions{
+		AspectRatio:      style.AspectRatio,
+		PersonGeneration: style.PersonGeneration,
+	})
+}
@@ -0,0 +1,131 @@
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Free API keys (ending in ":fx") must use api-free.deepl.com
+const (
+	deepLBaseURL     = "https://api.deepl.com"
+	deepLFreeBaseURL = "https://api-free.deepl.com"
+)
+
+// DeepLClient talks to the DeepL v2 API or a compatible server
+type DeepLClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func NewDeepLClient(baseURL, apiKey string) *DeepLClient {
+	if baseURL == "" {
+		baseURL = deepLBaseURL
+		if strings.HasSuffix(apiKey, ":fx") {
+			baseURL = deepLFreeBaseURL
+		}
+	}
+
+	return &DeepLClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{},
+	}
+}
+
+func (c *DeepLClient) Name() string {
+	return "deepl"
+}
+
+// SetTransport replaces the HTTP transport, e.g. for record/replay
+func (c *DeepLClient) SetTransport(rt http.RoundTripper) {
+	c.client.Transport = rt
+}
+
+type deepLRequest struct {
+	Text       []string `json:"text"`
+	SourceLang string   `json:"source_lang,omitempty"`
+	TargetLang string   `json:"target_lang"`
+}
+
+type deepLResponse struct {
+	Translations []struct {
+		Text string `json:"text"`
+	} `json:"translations"`
+}
+
+func (c *DeepLClient) Translate(ctx context.Context, texts []string, source, target string) ([]string, error) {
+	jsonBody, err := json.Marshal(deepLRequest{
+		Text:       texts,
+		SourceLang: deepLSource(source),
+		TargetLang: deepLTarget(target),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/v2/translate", bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "DeepL-Auth-Key "+c.apiKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var result deepLResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(result.Translations) != len(texts) {
+		return nil, fmt.Errorf("expected %d translations, got %d", len(texts), len(result.Translations))
+	}
+
+	out := make([]string, len(texts))
+	for i, t := range result.Translations {
+		out[i] = t.Text
+	}
+	return out, nil
+}
+
+// deepLSource returns the source language code, DeepL takes no region here
+func deepLSource(lang string) string {
+	base, _, _ := strings.Cut(lang, "-")
+	return strings.ToUpper(base)
+}
+
+// deepLTarget returns the target language code
+// English and Portuguese need a region, Chinese keeps its script, the others take the bare language
+func deepLTarget(lang string) string {
+	lang = strings.ToUpper(lang)
+	base, _, hasRegion := strings.Cut(lang, "-")
+	switch base {
+	case "EN", "PT":
+		if !hasRegion {
+			return map[string]string{"EN": "EN-US", "PT": "PT-PT"}[base]
+		}
+		return lang
+	case "ZH":
+		return lang
+	}
+	return base
+}
@@ -0,0 +1,101 @@
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// A self-hosted LibreTranslate server is the local stand-in for DeepL
+const libreTranslateBaseURL = "http://localhost:5000"
+
+// LibreTranslateClient talks to a LibreTranslate-compatible server
+type LibreTranslateClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func NewLibreTranslateClient(baseURL, apiKey string) *LibreTranslateClient {
+	if baseURL == "" {
+		baseURL = libreTranslateBaseURL
+	}
+
+	return &LibreTranslateClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{},
+	}
+}
+
+func (c *LibreTranslateClient) Name() string {
+	return "libretranslate"
+}
+
+// SetTransport replaces the HTTP transport, e.g. for record/replay
+func (c *LibreTranslateClient) SetTransport(rt http.RoundTripper) {
+	c.client.Transport = rt
+}
+
+type libreTranslateRequest struct {
+	Q      []string `json:"q"`
+	Source string   `json:"source"`
+	Target string   `json:"target"`
+	Format string   `json:"format"`
+	APIKey string   `json:"api_key,omitempty"`
+}
+
+type libreTranslateResponse struct {
+	TranslatedText []string `json:"translatedText"`
+	Error          string   `json:"error,omitempty"`
+}
+
+func (c *LibreTranslateClient) Translate(ctx context.Context, texts []string, source, target string) ([]string, error) {
+	jsonBody, err := json.Marshal(libreTranslateRequest{
+		Q:      texts,
+		Source: baseLanguage(source),
+		Target: baseLanguage(target),
+		Format: "text",
+		APIKey: c.apiKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/translate", bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var result libreTranslateResponse
+	if err := json.Unmarshal(body, &result); err != nil || resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	}
+	if len(result.TranslatedText) != len(texts) {
+		return nil, fmt.Errorf("expected %d translations, got %d", len(texts), len(result.TranslatedText))
+	}
+
+	return result.TranslatedText, nil
+}
+
+// baseLanguage strips the region, LibreTranslate only knows languages
+func baseLanguage(lang string) string {
+	base, _, _ := strings.Cut(strings.ToLower(lang), "-")
+	return base
+}
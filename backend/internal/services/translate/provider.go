@@ -0,0 +1,71 @@
+package translate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MaxBatch is the most texts sent in a single request
+const MaxBatch = 50
+
+// Provider machine-translates texts between languages
+type Provider interface {
+	// Translate returns one translation per text, in order
+	// Languages are BCP-47 tags such as "cs" or "en-US"
+	Translate(ctx context.Context, texts []string, source, target string) ([]string, error)
+	Name() string
+	SetTransport(rt http.RoundTripper)
+}
+
+// Config selects and configures a provider
+type Config struct {
+	Provider string // "deepl", "libretranslate" or "pseudo"
+	BaseURL  string // Empty uses the provider default
+	APIKey   string
+}
+
+// New creates the provider named in cfg
+func New(cfg Config) (Provider, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "deepl":
+		return NewDeepLClient(cfg.BaseURL, cfg.APIKey), nil
+	case "libretranslate":
+		return NewLibreTranslateClient(cfg.BaseURL, cfg.APIKey), nil
+	case "pseudo":
+		return Pseudo{}, nil
+	}
+	return nil, fmt.Errorf("unknown translation provider: %s", cfg.Provider)
+}
+
+// Batches splits texts into chunks of at most MaxBatch
+func Batches(texts []string) [][]string {
+	var batches [][]string
+	for len(texts) > MaxBatch {
+		batches = append(batches, texts[:MaxBatch])
+		texts = texts[MaxBatch:]
+	}
+	if len(texts) > 0 {
+		batches = append(batches, texts)
+	}
+	return batches
+}
+
+// Pseudo is an offline stand-in that tags texts with the target language,
+// e.g. "[en] Dobrý den", so the review flow works without a provider account
+type Pseudo struct{}
+
+func (Pseudo) Name() string {
+	return "pseudo"
+}
+
+func (Pseudo) SetTransport(http.RoundTripper) {}
+
+func (Pseudo) Translate(ctx context.Context, texts []string, source, target string) ([]string, error) {
+	out := make([]string, len(texts))
+	for i, text := range texts {
+		out[i] = "[" + target + "] " + text
+	}
+	return out, nil
+}
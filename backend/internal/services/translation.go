@@ -0,0 +1,409 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/example/duolingocards-backend/internal/models"
+	"github.com/example/duolingocards-backend/internal/services/locale"
+	"github.com/example/duolingocards-backend/internal/services/translate"
+)
+
+// backLanguages returns the deck's backLanguage followed by the translations
+// every card has; partly translated languages are not offered
+func backLanguages(deck *models.Deck) []string {
+	counts := make(map[string]int)
+	for _, card := range deck.Cards {
+		for lang, text := range card.Translations {
+			if text != "" {
+				counts[lang]++
+			}
+		}
+	}
+
+	var translations []string
+	for lang, count := range counts {
+		if count == len(deck.Cards) && !strings.EqualFold(lang, deck.BackLanguage) {
+			translations = append(translations, lang)
+		}
+	}
+	sort.Strings(translations)
+
+	return append([]string{deck.BackLanguage}, translations...)
+}
+
+// deckMetadata returns store metadata for the preferred locales
+// Each field falls back along the matching localizations to the deck's own
+// name, description and thumbnail, which are in backLanguage
+func deckMetadata(deck *models.Deck, preferred []string) models.DeckLocalization {
+	own := models.DeckLocalization{
+		Name:         deck.Name,
+		Description:  deck.Description,
+		ThumbnailURL: deck.ThumbnailURL,
+	}
+
+	available := []string{deck.BackLanguage}
+	for tag := range deck.Localizations {
+		available = append(available, tag)
+	}
+	sort.Strings(available[1:])
+
+	var chain []models.DeckLocalization
+	for _, tag := range locale.Rank(preferred, available) {
+		if localization, ok := deck.Localizations[tag]; ok {
+			chain = append(chain, localization)
+		}
+		if strings.EqualFold(tag, deck.BackLanguage) {
+			chain = append(chain, own)
+		}
+	}
+	chain = append(chain, own)
+
+	var meta models.DeckLocalization
+	for _, localization := range chain {
+		meta.Name = cmp.Or(meta.Name, localization.Name)
+		meta.Description = cmp.Or(meta.Description, localization.Description)
+		meta.ThumbnailURL = cmp.Or(meta.ThumbnailURL, localization.ThumbnailURL)
+	}
+	return meta
+}
+
+// localizeDeck returns a copy of deck with metadata and cards in the best
+// match for preferred; cards fall back to deck.BackLanguage
+// Without preferred languages the copy keeps every translation, for editors
+func localizeDeck(deck *models.Deck, preferred []string) *models.Deck {
+	localized := *deck
+	localized.BackLanguages = backLanguages(deck)
+	if len(preferred) == 0 {
+		return &localized
+	}
+
+	meta := deckMetadata(deck, preferred)
+	localized.Name = meta.Name
+	localized.Description = meta.Description
+	localized.ThumbnailURL = meta.ThumbnailURL
+	localized.Localizations = nil
+
+	lang, ok := locale.Match(preferred, localized.BackLanguages)
+	if !ok {
+		lang = deck.BackLanguage
+	}
+	localized.BackLanguage = lang
+	localized.Cards = make([]models.Card, len(deck.Cards))
+	for i, card := range deck.Cards {
+		localized.Cards[i] = localizeCard(card, deck.BackLanguage, lang)
+	}
+	return &localized
+}
+
+// localizeCard shows card in lang; media is shared except back audio,
+// which is only available in the deck's backLanguage
+func localizeCard(card models.Card, backLanguage, lang string) models.Card {
+	translations := card.Translations
+	card.Translations = nil
+	card.Examples = append([]models.Example(nil), card.Examples...)
+	for i := range card.Examples {
+		example := &card.Examples[i]
+		if text, ok := example.Translations[lang]; ok && lang != backLanguage {
+			example.Translation = text
+		}
+		example.Translations = nil
+	}
+
+	if lang == backLanguage {
+		return card
+	}
+
+	if text, ok := translations[lang]; ok {
+		card.BackText = text
+		if card.Media != nil && card.Media.AudioBack != "" {
+			media := *card.Media
+			media.AudioBack = ""
+			card.Media = &media
+		}
+	}
+	return card
+}
+
+// pendingTranslation is a text waiting for the translation provider
+type pendingTranslation struct {
+	cardID    string
+	exampleID string // Empty for the card's backText
+	text      string
+}
+
+// TranslateDeck machine-translates backText and example translations into
+// lang for cards that have no translation yet and marks them for review
+// Media is shared, the deck keeps a single set of images and front audio
+func (g *Generator) TranslateDeck(ctx context.Context, deckID string, req models.DeckTranslateRequest) (*models.Deck, error) {
+	if g.translator == nil {
+		return nil, fmt.Errorf("translation provider not configured")
+	}
+
+	lang := locale.Canonical(req.Language)
+	if err := locale.Validate(lang); err != nil {
+		return nil, err
+	}
+
+	g.mu.RLock()
+	deck, ok := g.decks[deckID]
+	if !ok {
+		g.mu.RUnlock()
+		return nil, fmt.Errorf("deck not found: %s", deckID)
+	}
+	source := deck.BackLanguage
+	if strings.EqualFold(source, lang) {
+		g.mu.RUnlock()
+		return nil, fmt.Errorf("deck %s already has back language %s", deckID, lang)
+	}
+
+	var pending []pendingTranslation
+	for _, card := range deck.Cards {
+		if card.Translations[lang] == "" && card.BackText != "" {
+			pending = append(pending, pendingTranslation{cardID: card.ID, text: card.BackText})
+		}
+		for _, example := range card.Examples {
+			if example.Translations[lang] == "" && example.Translation != "" {
+				pending = append(pending, pendingTranslation{cardID: card.ID, exampleID: example.ID, text: example.Translation})
+			}
+		}
+	}
+	g.mu.RUnlock()
+
+	texts := make([]*string, len(pending))
+	for i := range pending {
+		texts[i] = &pending[i].text
+	}
+	if err := g.translateTexts(ctx, texts, source, lang); err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	deck, ok = g.decks[deckID]
+	if !ok {
+		return nil, fmt.Errorf("deck not found: %s", deckID)
+	}
+
+	// Cards may have been edited or removed while the provider was working
+	for _, p := range pending {
+		card := findCard(deck, p.cardID)
+		if card == nil {
+			continue
+		}
+		if p.exampleID == "" {
+			card.Translations = setTranslation(card.Translations, lang, p.text)
+		} else if example := findExample(card, p.exampleID); example != nil {
+			example.Translations = setTranslation(example.Translations, lang, p.text)
+		} else {
+			continue
+		}
+		card.NeedsReview = true
+	}
+
+	if err := g.saveDeck(deck); err != nil {
+		return nil, err
+	}
+	g.deckUpdated(deck)
+	return localizeDeck(deck, nil), nil
+}
+
+func setTranslation(translations map[string]string, lang, text string) map[string]string {
+	if translations == nil {
+		translations = make(map[string]string)
+	}
+	translations[lang] = text
+	return translations
+}
+
+// CloneDeck copies a deck into another back language, machine-translating
+// back texts, example translations, name and description
+// Existing translations into the new language are used as they are, the old
+// back texts move into translations; machine-translated cards are marked for
+// review. Images and front audio are shared with the source deck, back audio
+// is dropped since it is in the old language
+func (g *Generator) CloneDeck(ctx context.Context, deckID string, req models.DeckCloneRequest) (*models.Deck, error) {
+	if g.translator == nil {
+		return nil, fmt.Errorf("translation provider not configured")
+	}
+
+	target := locale.Canonical(req.BackLanguage)
+	if err := locale.Validate(target); err != nil {
+		return nil, fmt.Errorf("backLanguage: %w", err)
+	}
+
+	deck, err := g.copyDeck(deckID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(deck.BackLanguage, target) {
+		return nil, fmt.Errorf("deck %s already has back language %s", deckID, target)
+	}
+
+	deck.ID = req.ID
+	if deck.ID == "" {
+		deck.ID = deckID + "-" + strings.ToLower(target)
+	}
+	if err := validateDeckID(deck.ID); err != nil {
+		return nil, err
+	}
+	// Fail before paying for translations, CreateDeck checks again under the lock
+	g.mu.RLock()
+	_, exists := g.decks[deck.ID]
+	g.mu.RUnlock()
+	if exists {
+		return nil, fmt.Errorf("%w: %s", ErrDeckExists, deck.ID)
+	}
+
+	// Collect every text in one list so the provider is called in as few batches as possible
+	source := deck.BackLanguage
+	texts := []*string{&deck.Name, &deck.Description}
+	for i := range deck.Cards {
+		card := &deck.Cards[i]
+
+		var translated bool
+		card.Translations, translated = swapTranslation(&card.BackText, card.Translations, source, target)
+		if !translated {
+			texts = append(texts, &card.BackText)
+			card.NeedsReview = true
+		}
+
+		for j := range card.Examples {
+			example := &card.Examples[j]
+			example.Translations, translated = swapTranslation(&example.Translation, example.Translations, source, target)
+			if !translated && example.Translation != "" {
+				texts = append(texts, &example.Translation)
+				card.NeedsReview = true
+			}
+		}
+	}
+	if err := g.translateTexts(ctx, texts, source, target); err != nil {
+		return nil, err
+	}
+
+	deck.BackLanguage = target
+	deck.TTSBackVoiceID = ""
+	deck.CreatedAt = time.Time{}
+	for i := range deck.Cards {
+		card := &deck.Cards[i]
+		card.ImageCandidates = nil
+		if card.Media != nil {
+			card.Media.AudioBack = ""
+		}
+	}
+
+	if err := g.CreateDeck(deck); err != nil {
+		return nil, err
+	}
+	return deck, nil
+}
+
+// swapTranslation prepares text for a switch of the back language from source to target
+// The text is kept in translations under source and replaced by the translation
+// into target when there is one, which reports true
+func swapTranslation(text *string, translations map[string]string, source, target string) (map[string]string, bool) {
+	translated := translations[target]
+	delete(translations, target)
+	if *text != "" {
+		translations = setTranslation(translations, source, *text)
+	}
+
+	if translated == "" {
+		return translations, false
+	}
+	*text = translated
+	return translations, true
+}
+
+// copyDeck returns a deep copy of a stored deck
+func (g *Generator) copyDeck(deckID string) (*models.Deck, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	deck, ok := g.decks[deckID]
+	if !ok {
+		return nil, fmt.Errorf("deck not found: %s", deckID)
+	}
+
+	data, err := json.Marshal(deck)
+	if err != nil {
+		return nil, err
+	}
+	var clone models.Deck
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, err
+	}
+	return &clone, nil
+}
+
+// translateTexts replaces each non-empty text with its translation
+func (g *Generator) translateTexts(ctx context.Context, texts []*string, source, target string) error {
+	var pending []*string
+	var input []string
+	for _, text := range texts {
+		if strings.TrimSpace(*text) != "" {
+			pending = append(pending, text)
+			input = append(input, *text)
+		}
+	}
+
+	done := 0
+	for _, batch := range translate.Batches(input) {
+		translated, err := g.translator.Translate(ctx, batch, source, target)
+		if err != nil {
+			return fmt.Errorf("translation failed: %w", err)
+		}
+		for _, text := range translated {
+			*pending[done] = text
+			done++
+		}
+	}
+	return nil
+}
+
+// ReviewCard accepts a machine-translated card, optionally correcting its back text
+func (g *Generator) ReviewCard(deckID, cardID string, review models.CardReview) (*models.Card, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	deck, card, err := g.lookupCard(deckID, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if review.BackText != nil {
+		text := strings.TrimSpace(*review.BackText)
+		if text == "" {
+			return nil, fmt.Errorf("backText must not be empty")
+		}
+
+		lang := locale.Canonical(review.Language)
+		if lang != "" {
+			if err := locale.Validate(lang); err != nil {
+				return nil, err
+			}
+		}
+		switch {
+		case lang != "" && !strings.EqualFold(lang, deck.BackLanguage):
+			card.Translations = setTranslation(card.Translations, lang, text)
+		case text != card.BackText:
+			if card.Media != nil {
+				card.Media.AudioBack = ""
+			}
+			card.BackText = text
+		}
+	}
+	card.NeedsReview = false
+
+	if err := g.saveDeck(deck); err != nil {
+		return nil, err
+	}
+	g.deckUpdated(deck)
+	return card, nil
+}
@@ -0,0 +1,17 @@
+package translit
+
+import "strings"
+
+// Chinese romanizes Chinese text to pinyin through a dictionary of words and
+// characters (e.g. "你好	nǐ hǎo"), syllables are separated by spaces
+type Chinese struct {
+	Dictionary *Dictionary
+}
+
+func (c *Chinese) Romanize(text string) (string, error) {
+	reading, err := c.Dictionary.Convert(strings.TrimSpace(text), " ")
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(strings.Fields(reading), " "), nil
+}
@@ -0,0 +1,114 @@
+package translit
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Dictionary maps words to readings, loaded from a tab separated file:
+//
+//	# comment
+//	水	みず
+//	食べる	たべる
+//
+// Lookups use the longest entry matching at each position
+type Dictionary struct {
+	entries map[string]string
+	maxLen  int // Longest surface form, in runes
+}
+
+// LoadDictionary reads a dictionary file
+func LoadDictionary(path string) (*Dictionary, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	d := &Dictionary{entries: make(map[string]string)}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		surface, reading, ok := strings.Cut(text, "\t")
+		surface, reading = strings.TrimSpace(surface), strings.TrimSpace(reading)
+		if !ok || surface == "" || reading == "" {
+			return nil, fmt.Errorf("%s:%d: expected \"surface<TAB>reading\"", path, line)
+		}
+
+		d.entries[surface] = reading
+		d.maxLen = max(d.maxLen, utf8.RuneCountInString(surface))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// Len returns the number of entries
+func (d *Dictionary) Len() int {
+	return len(d.entries)
+}
+
+// Lookup returns the reading of a whole word
+func (d *Dictionary) Lookup(word string) (string, bool) {
+	reading, ok := d.entries[word]
+	return reading, ok
+}
+
+// match returns the reading and length of the longest entry starting at runes[i]
+func (d *Dictionary) match(runes []rune, i int) (string, int) {
+	for n := min(d.maxLen, len(runes)-i); n > 0; n-- {
+		if reading, ok := d.entries[string(runes[i:i+n])]; ok {
+			return reading, n
+		}
+	}
+	return "", 0
+}
+
+// Convert replaces dictionary words in text with their readings, joined by sep
+// Text outside the dictionary is copied unless it is Han script, which has
+// no reading of its own and fails with ErrNoReading
+func (d *Dictionary) Convert(text, sep string) (string, error) {
+	runes := []rune(text)
+	var parts []string
+	var plain strings.Builder
+
+	flush := func() {
+		if plain.Len() > 0 {
+			parts = append(parts, plain.String())
+			plain.Reset()
+		}
+	}
+
+	for i := 0; i < len(runes); {
+		if reading, n := d.match(runes, i); n > 0 {
+			flush()
+			parts = append(parts, reading)
+			i += n
+			continue
+		}
+
+		if unicode.Is(unicode.Han, runes[i]) {
+			return "", fmt.Errorf("%w for %q", ErrNoReading, string(runes[i]))
+		}
+		plain.WriteRune(runes[i])
+		i++
+	}
+	flush()
+
+	return strings.Join(parts, sep), nil
+}
+
+// ToKana lets a Japanese dictionary (surface -> kana) act as an Analyzer
+func (d *Dictionary) ToKana(text string) (string, error) {
+	return d.Convert(text, "")
+}
@@ -0,0 +1,191 @@
+package translit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Segment is a run of text with an optional ruby reading above it
+type Segment struct {
+	Base string
+	Ruby string // Empty for kana and other text that needs no annotation
+}
+
+// Annotator produces ruby segments for text in a language
+type Annotator interface {
+	Furigana(text, reading string) ([]Segment, error)
+}
+
+type runKind int
+
+const (
+	runOther runKind = iota // Punctuation, Latin, digits: not part of the reading
+	runKana
+	runKanji
+)
+
+type textRun struct {
+	kind runKind
+	text []rune
+}
+
+// Furigana splits text into kanji runs annotated with their kana reading
+// and plain kana runs. reading may be romaji or kana; when empty it is computed
+// with the analyzer. The analyzer also resolves ambiguous splits, e.g. which
+// part of "にほんご" belongs to which kanji. Returns nil for text without kanji.
+func (j *Japanese) Furigana(text, reading string) ([]Segment, error) {
+	runs := splitRuns(text)
+	hasKanji := false
+	for _, run := range runs {
+		hasKanji = hasKanji || run.kind == runKanji
+	}
+	if !hasKanji {
+		return nil, nil
+	}
+
+	kana, err := j.readingKana(text, reading)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &aligner{
+		japanese: j,
+		runs:     runs,
+		reading:  []rune(kana),
+		ruby:     make([]string, len(runs)),
+	}
+	if !a.align(0, 0) {
+		return nil, fmt.Errorf("%w: reading %q does not fit %q", ErrNoReading, reading, text)
+	}
+
+	var segments []Segment
+	for i, run := range runs {
+		if run.kind != runKanji && len(segments) > 0 && segments[len(segments)-1].Ruby == "" {
+			segments[len(segments)-1].Base += string(run.text)
+			continue
+		}
+		segments = append(segments, Segment{Base: string(run.text), Ruby: a.ruby[i]})
+	}
+	return segments, nil
+}
+
+// readingKana returns the reading in hiragana, converting romaji when needed
+func (j *Japanese) readingKana(text, reading string) (string, error) {
+	if reading == "" {
+		return j.Kana(text)
+	}
+
+	for _, r := range reading {
+		if IsKana(r) {
+			return ToHiragana(reading), nil
+		}
+	}
+
+	kana, err := RomajiToHiragana(reading)
+	if err != nil {
+		// Readings typed by editors may not be valid romaji, fall back to the analyzer
+		return j.Kana(text)
+	}
+	return kana, nil
+}
+
+func splitRuns(text string) []textRun {
+	var runs []textRun
+	for _, r := range text {
+		kind := runOther
+		switch {
+		case IsKanji(r):
+			kind = runKanji
+		case IsKana(r):
+			kind = runKana
+		}
+
+		if n := len(runs); n > 0 && runs[n-1].kind == kind {
+			runs[n-1].text = append(runs[n-1].text, r)
+			continue
+		}
+		runs = append(runs, textRun{kind: kind, text: []rune{r}})
+	}
+	return runs
+}
+
+// aligner assigns a part of the reading to every kanji run, using kana runs as anchors
+type aligner struct {
+	japanese *Japanese
+	runs     []textRun
+	reading  []rune
+	ruby     []string
+}
+
+func (a *aligner) align(i, pos int) bool {
+	if i == len(a.runs) {
+		return pos == len(a.reading)
+	}
+
+	run := a.runs[i]
+	switch run.kind {
+	case runOther:
+		return a.align(i+1, pos)
+	case runKana:
+		kana := []rune(ToHiragana(string(run.text)))
+		if pos+len(kana) > len(a.reading) || !kanaMatch(kana, a.reading[pos:pos+len(kana)]) {
+			return false
+		}
+		return a.align(i+1, pos+len(kana))
+	}
+
+	for _, n := range a.candidates(run, pos) {
+		a.ruby[i] = string(a.reading[pos : pos+n])
+		if a.align(i+1, pos+n) {
+			return true
+		}
+	}
+	a.ruby[i] = ""
+	return false
+}
+
+// candidates returns reading lengths to try for a kanji run at pos
+// The analyzer's reading for the run comes first, then every other length
+func (a *aligner) candidates(run textRun, pos int) []int {
+	remaining := len(a.reading) - pos
+	var lengths []int
+
+	preferred := 0
+	if a.japanese.Analyzer != nil {
+		if kana, err := a.japanese.Analyzer.ToKana(string(run.text)); err == nil {
+			kana := []rune(ToHiragana(kana))
+			if len(kana) <= remaining && kanaMatch(kana, a.reading[pos:pos+len(kana)]) {
+				preferred = len(kana)
+				lengths = append(lengths, preferred)
+			}
+		}
+	}
+
+	for n := 1; n <= remaining; n++ {
+		if n != preferred {
+			lengths = append(lengths, n)
+		}
+	}
+	return lengths
+}
+
+// kanaEquivalents are spelled differently from how they are read,
+// so a romaji reading converts them to their pronunciation
+var kanaEquivalents = map[rune]rune{
+	'は': 'わ', 'へ': 'え', 'を': 'お', 'ぢ': 'じ', 'づ': 'ず',
+}
+
+func kanaMatch(text, reading []rune) bool {
+	for i := range text {
+		t, r := text[i], reading[i]
+		if t == r || kanaEquivalents[t] == r {
+			continue
+		}
+		// A long vowel mark stands for any vowel
+		if t == 'ー' && strings.ContainsRune("あいうえお", r) {
+			continue
+		}
+		return false
+	}
+	return true
+}
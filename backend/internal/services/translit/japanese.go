@@ -0,0 +1,44 @@
+package translit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Analyzer converts Japanese text containing kanji to kana
+// A morphological analyzer can be plugged in here; *Dictionary is the built-in one
+type Analyzer interface {
+	ToKana(text string) (string, error)
+}
+
+// Japanese romanizes Japanese text to Hepburn romaji
+// Kana is converted directly, kanji needs an Analyzer
+type Japanese struct {
+	Analyzer Analyzer
+}
+
+// Kana returns the reading of text in hiragana
+func (j *Japanese) Kana(text string) (string, error) {
+	if j.Analyzer != nil {
+		kana, err := j.Analyzer.ToKana(text)
+		if err != nil {
+			return "", err
+		}
+		text = kana
+	}
+
+	for _, r := range text {
+		if IsKanji(r) {
+			return "", fmt.Errorf("%w for %q: no analyzer for kanji", ErrNoReading, string(r))
+		}
+	}
+	return ToHiragana(text), nil
+}
+
+func (j *Japanese) Romanize(text string) (string, error) {
+	kana, err := j.Kana(strings.TrimSpace(text))
+	if err != nil {
+		return "", err
+	}
+	return KanaToRomaji(kana), nil
+}
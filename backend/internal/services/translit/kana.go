@@ -0,0 +1,262 @@
+package translit
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// hepburn maps hiragana to modified Hepburn romanization
+// Long vowels are written out (おう -> ou) like the readings in the bundled decks
+var hepburn = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o",
+	'ん': "n", 'ゔ': "vu",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+	'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo", 'ゎ': "wa",
+}
+
+var punctuation = map[rune]string{
+	'、': ", ", '。': ". ", '！': "! ", '？': "? ",
+	'「': "\"", '」': "\"", '・': " ", '　': " ",
+}
+
+// ToHiragana converts katakana in text to hiragana
+func ToHiragana(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'ァ' && r <= 'ヶ' {
+			return r - 0x60
+		}
+		return r
+	}, text)
+}
+
+// IsKana reports whether r is hiragana, katakana or the long vowel mark
+func IsKana(r rune) bool {
+	return unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || r == 'ー'
+}
+
+// IsKanji reports whether r is a Han character
+func IsKanji(r rune) bool {
+	return unicode.Is(unicode.Han, r) || r == '々'
+}
+
+// KanaToRomaji converts hiragana and katakana to Hepburn romaji
+// Other characters are copied unchanged
+func KanaToRomaji(text string) string {
+	runes := []rune(ToHiragana(text))
+	var syllables []string
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if s, ok := punctuation[r]; ok {
+			syllables = append(syllables, s)
+			continue
+		}
+
+		switch r {
+		case 'っ':
+			// Sokuon doubles the next consonant, handled when that syllable is added
+			syllables = append(syllables, "っ")
+			continue
+		case 'ー':
+			syllables = append(syllables, lastVowel(syllables))
+			continue
+		}
+
+		s, ok := hepburn[r]
+		if !ok {
+			syllables = append(syllables, string(r))
+			continue
+		}
+
+		// Small kana combine with the previous syllable: きゃ, しゅ, ファ, ティ
+		if i+1 < len(runes) {
+			if combined, ok := combine(s, runes[i+1]); ok {
+				s = combined
+				i++
+			}
+		}
+		syllables = append(syllables, s)
+	}
+
+	var b strings.Builder
+	for i, s := range syllables {
+		next := ""
+		if i+1 < len(syllables) {
+			next = syllables[i+1]
+		}
+
+		switch {
+		case s == "っ":
+			if next == "" || isVowel(rune(next[0])) {
+				continue
+			}
+			if strings.HasPrefix(next, "ch") {
+				b.WriteByte('t')
+			} else {
+				b.WriteByte(next[0])
+			}
+		case s == "n" && next != "" && (isVowel(rune(next[0])) || next[0] == 'y'):
+			// ん before a vowel or y is marked to keep syllables apart (kon'ya)
+			b.WriteString("n'")
+		default:
+			b.WriteString(s)
+		}
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+// combine joins a syllable with a following small kana
+func combine(s string, small rune) (string, bool) {
+	switch small {
+	case 'ゃ', 'ゅ', 'ょ':
+		if !strings.HasSuffix(s, "i") || len(s) < 2 {
+			return "", false
+		}
+		vowel := hepburn[small][1:]
+		switch s {
+		case "shi", "chi", "ji":
+			return s[:len(s)-1] + vowel, true
+		}
+		return s[:len(s)-1] + "y" + vowel, true
+	case 'ぁ', 'ぃ', 'ぅ', 'ぇ', 'ぉ':
+		vowel := hepburn[small]
+		switch s {
+		case "shi", "chi", "ji":
+			return s[:len(s)-1] + vowel, true
+		case "fu", "vu":
+			return s[:1] + vowel, true
+		case "te", "de":
+			return s[:1] + vowel, true
+		case "u":
+			return "w" + vowel, true
+		}
+	}
+	return "", false
+}
+
+// lastVowel returns the vowel a long vowel mark repeats
+func lastVowel(syllables []string) string {
+	if len(syllables) == 0 {
+		return ""
+	}
+	last := syllables[len(syllables)-1]
+	if last == "" || !isVowel(rune(last[len(last)-1])) {
+		return ""
+	}
+	return last[len(last)-1:]
+}
+
+// romajiSyllables maps Hepburn syllables back to hiragana
+// Historical and duplicate kana (ゐ, ゑ, を, ぢ, づ) are left out
+var romajiSyllables = func() map[string]rune {
+	m := make(map[string]rune)
+	for r, s := range hepburn {
+		switch r {
+		case 'ぁ', 'ぃ', 'ぅ', 'ぇ', 'ぉ', 'ゃ', 'ゅ', 'ょ', 'ゎ', 'ゐ', 'ゑ', 'を', 'ぢ', 'づ':
+			continue
+		}
+		m[s] = r
+	}
+	return m
+}()
+
+// longVowels spells out macrons so "tōkyō" converts to とうきょう
+var longVowels = strings.NewReplacer(
+	"ā", "aa", "ī", "ii", "ū", "uu", "ē", "ee", "ō", "ou",
+	"â", "aa", "î", "ii", "û", "uu", "ê", "ee", "ô", "ou",
+)
+
+// RomajiToHiragana converts a Hepburn reading back to hiragana
+// Spaces and punctuation are dropped; returns ErrNoReading for text that is not romaji
+func RomajiToHiragana(romaji string) (string, error) {
+	text := longVowels.Replace(strings.ToLower(romaji))
+	var b strings.Builder
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c < 'a' || c > 'z':
+			if c >= 0x80 {
+				return "", fmt.Errorf("%w: %q is not romaji", ErrNoReading, romaji)
+			}
+			i++
+			continue
+		case c == 'n' && (i+1 == len(text) || !isVowel(rune(text[i+1])) && text[i+1] != 'y'):
+			b.WriteRune('ん')
+			i++
+			continue
+		case i+1 < len(text) && c == text[i+1] && !isVowel(rune(c)),
+			c == 't' && strings.HasPrefix(text[i+1:], "ch"):
+			b.WriteRune('っ')
+			i++
+			continue
+		}
+
+		matched := false
+		for n := min(3, len(text)-i); n >= 1; n-- {
+			syllable := text[i : i+n]
+			kana, ok := "", false
+			if r, found := romajiSyllables[syllable]; found {
+				kana, ok = string(r), true
+			} else {
+				kana, ok = yoon(syllable)
+			}
+			if ok {
+				b.WriteString(kana)
+				i += n
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return "", fmt.Errorf("%w: %q is not romaji", ErrNoReading, romaji)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// yoon converts a contracted syllable such as "kya" or "sho" to kana
+func yoon(syllable string) (string, bool) {
+	if len(syllable) < 2 {
+		return "", false
+	}
+	small, ok := map[byte]rune{'a': 'ゃ', 'u': 'ゅ', 'o': 'ょ'}[syllable[len(syllable)-1]]
+	if !ok {
+		return "", false
+	}
+
+	head := syllable[:len(syllable)-1]
+	switch head {
+	case "sh", "ch", "j":
+		head += "i"
+	default:
+		if !strings.HasSuffix(head, "y") {
+			return "", false
+		}
+		head = head[:len(head)-1] + "i"
+	}
+
+	r, ok := romajiSyllables[head]
+	if !ok {
+		return "", false
+	}
+	return string(r) + string(small), true
+}
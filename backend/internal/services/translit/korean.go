@@ -0,0 +1,101 @@
+package translit
+
+import "strings"
+
+// Korean romanizes Hangul using the Revised Romanization of Korean
+// Covers liaison and nasalization, other sound changes are not applied
+type Korean struct{}
+
+const (
+	hangulBase   = 0xAC00
+	hangulLast   = 0xD7A3
+	hangulMedial = 21
+	hangulFinal  = 28
+
+	initialSilent = 11 // ㅇ
+	initialN      = 2  // ㄴ
+	initialM      = 6  // ㅁ
+)
+
+var hangulInitials = []string{
+	"g", "kk", "n", "d", "tt", "r", "m", "b", "pp", "s",
+	"ss", "", "j", "jj", "ch", "k", "t", "p", "h",
+}
+
+var hangulMedials = []string{
+	"a", "ae", "ya", "yae", "eo", "e", "yeo", "ye", "o", "wa", "wae",
+	"oe", "yo", "u", "wo", "we", "wi", "yu", "eu", "ui", "i",
+}
+
+// hangulFinals are the representative sounds of final consonants
+var hangulFinals = []string{
+	"", "k", "k", "k", "n", "n", "n", "t", "l", "k", "m", "l", "l", "l",
+	"p", "l", "m", "p", "p", "t", "t", "ng", "t", "t", "k", "t", "p", "t",
+}
+
+// hangulLiaison is how a single final consonant is read when the next syllable
+// starts with a silent ㅇ; compound finals and ㅇ itself are not carried over
+var hangulLiaison = map[int]string{
+	1: "g", 2: "kk", 4: "n", 7: "d", 8: "r", 16: "m", 17: "b",
+	19: "s", 20: "ss", 22: "j", 23: "ch", 24: "k", 25: "t", 26: "p", 27: "",
+}
+
+// nasalized finals before ㄴ or ㅁ
+var hangulNasal = map[string]string{"k": "ng", "t": "n", "p": "m"}
+
+func (Korean) Romanize(text string) (string, error) {
+	runes := []rune(strings.TrimSpace(text))
+	var b strings.Builder
+
+	for i, r := range runes {
+		if r < hangulBase || r > hangulLast {
+			b.WriteRune(r)
+			continue
+		}
+
+		index := int(r - hangulBase)
+		initial := index / (hangulMedial * hangulFinal)
+		medial := index % (hangulMedial * hangulFinal) / hangulFinal
+		final := index % hangulFinal
+
+		// A final carried over by the previous syllable replaces the silent initial
+		if initial != initialSilent || !carriesOver(runes, i-1) {
+			b.WriteString(hangulInitials[initial])
+		}
+		b.WriteString(hangulMedials[medial])
+
+		if final == 0 {
+			continue
+		}
+
+		nextInitial := -1
+		if i+1 < len(runes) && runes[i+1] >= hangulBase && runes[i+1] <= hangulLast {
+			nextInitial = int(runes[i+1]-hangulBase) / (hangulMedial * hangulFinal)
+		}
+
+		switch liaison, ok := hangulLiaison[final]; {
+		case nextInitial == initialSilent && ok:
+			b.WriteString(liaison)
+		case nextInitial == initialN || nextInitial == initialM:
+			sound := hangulFinals[final]
+			if nasal, ok := hangulNasal[sound]; ok {
+				sound = nasal
+			}
+			b.WriteString(sound)
+		default:
+			b.WriteString(hangulFinals[final])
+		}
+	}
+
+	return b.String(), nil
+}
+
+// carriesOver reports whether the syllable at i moves its final consonant
+// onto a following silent ㅇ
+func carriesOver(runes []rune, i int) bool {
+	if i < 0 || runes[i] < hangulBase || runes[i] > hangulLast {
+		return false
+	}
+	_, ok := hangulLiaison[int(runes[i]-hangulBase)%hangulFinal]
+	return ok
+}
@@ -0,0 +1,89 @@
+package translit
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ErrNoReading is returned when part of a text cannot be transliterated,
+// e.g. kanji missing from the dictionary
+var ErrNoReading = errors.New("no reading")
+
+// Romanizer produces the Latin-script reading of text in one language
+type Romanizer interface {
+	Romanize(text string) (string, error)
+}
+
+// Romanizers that need no external data are always available
+// Dictionary based ones (kanji, pinyin) are registered at startup
+var romanizers = map[string]Romanizer{
+	"ja": &Japanese{},
+	"ko": Korean{},
+}
+
+// Register makes a romanizer available for a language, replacing any existing one
+func Register(lang string, romanizer Romanizer) {
+	romanizers[baseLanguage(lang)] = romanizer
+}
+
+// For returns the romanizer for a language tag such as "ja" or "ja-JP"
+func For(lang string) (Romanizer, bool) {
+	romanizer, ok := romanizers[baseLanguage(lang)]
+	return romanizer, ok
+}
+
+func baseLanguage(lang string) string {
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
+
+// NeedsReading reports whether text contains letters outside the Latin script
+func NeedsReading(text string) bool {
+	for _, r := range text {
+		if unicode.IsLetter(r) && !unicode.Is(unicode.Latin, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// macrons maps long vowels to their plain form for comparison
+var macrons = strings.NewReplacer(
+	"ā", "a", "ī", "i", "ū", "u", "ē", "e", "ō", "o",
+	"â", "a", "î", "i", "û", "u", "ê", "e", "ô", "o",
+)
+
+// Normalize reduces a reading to a form that ignores spelling conventions:
+// case, spacing, punctuation and how long vowels are written
+// ("arigatō", "arigatou" and "Arigato" all normalize to "arigato")
+func Normalize(reading string) string {
+	reading = macrons.Replace(strings.ToLower(reading))
+
+	var b strings.Builder
+	var last rune
+	for _, r := range reading {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			continue
+		}
+		// Long vowels: oo, ou, uu, aa, ii, ee collapse to a single vowel
+		if isVowel(last) && (r == last || (last == 'o' && r == 'u')) {
+			continue
+		}
+		b.WriteRune(r)
+		last = r
+	}
+	return b.String()
+}
+
+// Matches reports whether two readings are the same after normalization
+func Matches(a, b string) bool {
+	return Normalize(a) == Normalize(b)
+}
+
+func isVowel(r rune) bool {
+	return strings.ContainsRune("aiueo", r)
+}
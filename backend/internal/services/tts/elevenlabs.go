@@ -2,18 +2,43 @@ package tts
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 )
 
 const (
 	elevenLabsBaseURL = "https://api.elevenlabs.io/v1"
 	// Japanese voice - you can change this to other voice IDs
 	defaultVoiceID = "21m00Tcm4TlvDq8ikWAM" // Rachel - works well for multiple languages
+
+	defaultModelID         = "eleven_multilingual_v2" // Supports Japanese
+	defaultStability       = 0.5
+	defaultSimilarityBoost = 0.75
+	defaultOutputFormat    = "mp3_44100_128"
 )
 
+// Models accepted by ValidateOptions
+var supportedModels = map[string]bool{
+	"eleven_multilingual_v2": true,
+	"eleven_turbo_v2_5":      true,
+	"eleven_flash_v2_5":      true,
+	"eleven_v3":              true,
+}
+
+// Output formats accepted by ValidateOptions (codec_samplerate_bitrate)
+var supportedOutputFormats = map[string]bool{
+	"mp3_22050_32":  true,
+	"mp3_44100_32":  true,
+	"mp3_44100_64":  true,
+	"mp3_44100_96":  true,
+	"mp3_44100_128": true,
+	"mp3_44100_192": true,
+}
+
 type ElevenLabsClient struct {
 	apiKey  string
 	voiceID string
@@ -32,6 +57,11 @@ func (c *ElevenLabsClient) SetVoiceID(voiceID string) {
 	c.voiceID = voiceID
 }
 
+// SetTransport replaces the HTTP transport, e.g. to add rate limiting
+func (c *ElevenLabsClient) SetTransport(rt http.RoundTripper) {
+	c.client.Transport = rt
+}
+
 type ttsRequest struct {
 	Text          string        `json:"text"`
 	ModelID       string        `json:"model_id"`
@@ -39,33 +69,103 @@ type ttsRequest struct {
 }
 
 type voiceSettings struct {
-	Stability       float64 `json:"stability"`
-	SimilarityBoost float64 `json:"similarity_boost"`
+	Stability       float64  `json:"stability"`
+	SimilarityBoost float64  `json:"similarity_boost"`
+	Style           *float64 `json:"style,omitempty"`
+	UseSpeakerBoost *bool    `json:"use_speaker_boost,omitempty"`
+	Speed           float64  `json:"speed,omitempty"`
+}
+
+// SpeechOptions tunes a single synthesis request
+// Nil or empty fields fall back to the client defaults
+type SpeechOptions struct {
+	ModelID         string
+	Stability       *float64
+	SimilarityBoost *float64
+	Style           *float64
+	UseSpeakerBoost *bool
+	OutputFormat    string // e.g. "mp3_44100_128"
+
+	// Speed is the playback speed multiplier (0.7-1.2), zero means normal speed
+	Speed float64
+}
+
+// ValidateOptions checks that opts only contains values the provider accepts
+func ValidateOptions(opts SpeechOptions) error {
+	if opts.ModelID != "" && !supportedModels[opts.ModelID] {
+		return fmt.Errorf("unsupported tts model: %s", opts.ModelID)
+	}
+	if opts.OutputFormat != "" && !supportedOutputFormats[opts.OutputFormat] {
+		return fmt.Errorf("unsupported tts output format: %s", opts.OutputFormat)
+	}
+
+	ranges := []struct {
+		name  string
+		value *float64
+	}{
+		{"stability", opts.Stability},
+		{"similarityBoost", opts.SimilarityBoost},
+		{"style", opts.Style},
+	}
+	for _, r := range ranges {
+		if r.value != nil && (*r.value < 0 || *r.value > 1) {
+			return fmt.Errorf("tts %s must be between 0 and 1, got %v", r.name, *r.value)
+		}
+	}
+
+	if opts.Speed != 0 && (opts.Speed < 0.7 || opts.Speed > 1.2) {
+		return fmt.Errorf("tts speed must be between 0.7 and 1.2, got %v", opts.Speed)
+	}
+
+	return nil
 }
 
 // GenerateSpeech generates audio for the given text
 // If voiceID is empty, uses the default voice
 func (c *ElevenLabsClient) GenerateSpeech(text string, voiceID string) ([]byte, error) {
+	return c.GenerateSpeechWithOptions(context.Background(), text, voiceID, SpeechOptions{})
+}
+
+// GenerateSpeechWithOptions generates audio for the given text using opts
+// The request is aborted when ctx is cancelled
+func (c *ElevenLabsClient) GenerateSpeechWithOptions(ctx context.Context, text string, voiceID string, opts SpeechOptions) ([]byte, error) {
 	if voiceID == "" {
 		voiceID = c.voiceID
 	}
-	url := fmt.Sprintf("%s/text-to-speech/%s", elevenLabsBaseURL, voiceID)
+
+	outputFormat := opts.OutputFormat
+	if outputFormat == "" {
+		outputFormat = defaultOutputFormat
+	}
+	endpoint := fmt.Sprintf("%s/text-to-speech/%s?output_format=%s", elevenLabsBaseURL, url.PathEscape(voiceID), url.QueryEscape(outputFormat))
 
 	reqBody := ttsRequest{
 		Text:    text,
-		ModelID: "eleven_multilingual_v2", // Supports Japanese
+		ModelID: opts.ModelID,
 		VoiceSettings: voiceSettings{
-			Stability:       0.5,
-			SimilarityBoost: 0.75,
+			Stability:       defaultStability,
+			SimilarityBoost: defaultSimilarityBoost,
+			Style:           opts.Style,
+			UseSpeakerBoost: opts.UseSpeakerBoost,
+			Speed:           opts.Speed,
 		},
 	}
+	if reqBody.ModelID == "" {
+		reqBody.ModelID = defaultModelID
+	}
+	if opts.Stability != nil {
+		reqBody.VoiceSettings.Stability = *opts.Stability
+	}
+	if opts.SimilarityBoost != nil {
+		reqBody.VoiceSettings.SimilarityBoost = *opts.SimilarityBoost
+	}
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewReader(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(jsonBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -87,3 +187,62 @@ func (c *ElevenLabsClient) GenerateSpeech(text string, voiceID string) ([]byte,
 
 	return io.ReadAll(resp.Body)
 }
+
+// Voice is a provider voice available to deck editors
+type Voice struct {
+	VoiceID    string            `json:"voiceId"`
+	Name       string            `json:"name"`
+	Category   string            `json:"category,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	PreviewURL string            `json:"previewUrl,omitempty"`
+}
+
+type voicesResponse struct {
+	Voices []struct {
+		VoiceID    string            `json:"voice_id"`
+		Name       string            `json:"name"`
+		Category   string            `json:"category"`
+		Labels     map[string]string `json:"labels"`
+		PreviewURL string            `json:"preview_url"`
+	} `json:"voices"`
+}
+
+// ListVoices returns the voices available to the API key
+func (c *ElevenLabsClient) ListVoices() ([]Voice, error) {
+	req, err := http.NewRequest("GET", elevenLabsBaseURL+"/voices", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("xi-api-key", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var result voicesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	voices := make([]Voice, 0, len(result.Voices))
+	for _, v := range result.Voices {
+		voices = append(voices, Voice{
+			VoiceID:    v.VoiceID,
+			Name:       v.Name,
+			Category:   v.Category,
+			Labels:     v.Labels,
+			PreviewURL: v.PreviewURL,
+		})
+	}
+
+	return voices, nil
+}
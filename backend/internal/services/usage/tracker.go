@@ -0,0 +1,226 @@
+package usage
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Providers usage is recorded for
+const (
+	ProviderElevenLabs = "elevenlabs"
+	ProviderImagen     = "imagen"
+)
+
+// dateLayout keys usage by UTC day
+const dateLayout = "2006-01-02"
+
+// Pricing converts provider units into cost (USD)
+type Pricing struct {
+	TTSPerThousandChars float64
+	ImagePrice          float64
+}
+
+// Cost returns the price of units for provider
+// Units are characters for TTS and images for Imagen
+func (p Pricing) Cost(provider string, units int64) float64 {
+	switch provider {
+	case ProviderElevenLabs:
+		return float64(units) / 1000 * p.TTSPerThousandChars
+	case ProviderImagen:
+		return float64(units) * p.ImagePrice
+	}
+	return 0
+}
+
+// Record is the usage of one provider for one deck on one day
+type Record struct {
+	Date     string  `json:"date"` // YYYY-MM-DD, UTC
+	DeckID   string  `json:"deckId"`
+	Provider string  `json:"provider"`
+	Units    int64   `json:"units"` // Characters for TTS, images for Imagen
+	Requests int     `json:"requests"`
+	Cost     float64 `json:"cost"`
+}
+
+// Filter selects records for a report, empty fields match everything
+type Filter struct {
+	From     string // YYYY-MM-DD, inclusive
+	To       string // YYYY-MM-DD, inclusive
+	DeckID   string
+	Provider string
+}
+
+func (f Filter) matches(r *Record) bool {
+	return (f.From == "" || r.Date >= f.From) &&
+		(f.To == "" || r.Date <= f.To) &&
+		(f.DeckID == "" || r.DeckID == f.DeckID) &&
+		(f.Provider == "" || r.Provider == f.Provider)
+}
+
+// Report aggregates usage records matching a filter
+type Report struct {
+	From       string             `json:"from,omitempty"`
+	To         string             `json:"to,omitempty"`
+	Records    []Record           `json:"records"`
+	ByProvider map[string]float64 `json:"byProvider"`
+	ByDeck     map[string]float64 `json:"byDeck"`
+	TotalCost  float64            `json:"totalCost"`
+	Currency   string             `json:"currency"`
+}
+
+type recordKey struct {
+	date, deckID, provider string
+}
+
+// Tracker accumulates provider usage and stores it as a JSON file
+type Tracker struct {
+	path    string
+	pricing Pricing
+
+	records map[recordKey]*Record
+	mu      sync.Mutex
+}
+
+// NewTracker keeps usage records in usage.json under dir
+func NewTracker(dir string, pricing Pricing) *Tracker {
+	t := &Tracker{
+		path:    filepath.Join(dir, "usage.json"),
+		pricing: pricing,
+		records: make(map[recordKey]*Record),
+	}
+	t.load()
+	return t
+}
+
+func (t *Tracker) load() {
+	data, err := os.ReadFile(t.path)
+	if err != nil {
+		return
+	}
+
+	var records []*Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		log.Printf("Usage: failed to parse %s: %v", t.path, err)
+		return
+	}
+	for _, r := range records {
+		t.records[recordKey{r.Date, r.DeckID, r.Provider}] = r
+	}
+}
+
+// persist writes all records to disk, t.mu must be held
+func (t *Tracker) persist() {
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		log.Printf("Usage: failed to create directory: %v", err)
+		return
+	}
+
+	data, err := json.MarshalIndent(t.sorted(Filter{}), "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(t.path, data, 0644); err != nil {
+		log.Printf("Usage: failed to write %s: %v", t.path, err)
+	}
+}
+
+// sorted returns copies of matching records ordered by date, deck and provider
+func (t *Tracker) sorted(filter Filter) []Record {
+	records := []Record{}
+	for _, r := range t.records {
+		if filter.matches(r) {
+			records = append(records, *r)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.DeckID != b.DeckID {
+			return a.DeckID < b.DeckID
+		}
+		return a.Provider < b.Provider
+	})
+	return records
+}
+
+// Pricing returns the prices used to cost usage
+func (t *Tracker) Pricing() Pricing {
+	return t.pricing
+}
+
+// Record adds one successful provider request and returns its cost
+func (t *Tracker) Record(deckID, provider string, units int64) float64 {
+	cost := t.pricing.Cost(provider, units)
+	key := recordKey{time.Now().UTC().Format(dateLayout), deckID, provider}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r, ok := t.records[key]
+	if !ok {
+		r = &Record{Date: key.date, DeckID: deckID, Provider: provider}
+		t.records[key] = r
+	}
+	r.Units += units
+	r.Requests++
+	r.Cost += cost
+
+	t.persist()
+	return cost
+}
+
+// MonthToDate returns the cost recorded in the current UTC calendar month
+func (t *Tracker) MonthToDate() float64 {
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return t.Report(Filter{From: from.Format(dateLayout)}).TotalCost
+}
+
+// Report returns the records matching filter with totals
+func (t *Tracker) Report(filter Filter) *Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := &Report{
+		From:       filter.From,
+		To:         filter.To,
+		Records:    t.sorted(filter),
+		ByProvider: make(map[string]float64),
+		ByDeck:     make(map[string]float64),
+		Currency:   "USD",
+	}
+	for _, r := range report.Records {
+		report.ByProvider[r.Provider] += r.Cost
+		report.ByDeck[r.DeckID] += r.Cost
+		report.TotalCost += r.Cost
+	}
+
+	for k, v := range report.ByProvider {
+		report.ByProvider[k] = Round(v)
+	}
+	for k, v := range report.ByDeck {
+		report.ByDeck[k] = Round(v)
+	}
+	report.TotalCost = Round(report.TotalCost)
+	return report
+}
+
+// Round drops float noise from summed costs, keeping micro-dollar precision
+func Round(cost float64) float64 {
+	return math.Round(cost*1e6) / 1e6
+}
+
+// ValidDate reports whether s is a YYYY-MM-DD date
+func ValidDate(s string) bool {
+	_, err := time.Parse(dateLayout, s)
+	return err == nil
+}
@@ -0,0 +1,468 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Event names sent to subscribers
+const (
+	GenerationCompleted = "generation.completed"
+	GenerationFailed    = "generation.failed"
+	DeckUpdated         = "deck.updated"
+	PurchaseVerified    = "purchase.verified"
+)
+
+var knownEvents = map[string]bool{
+	GenerationCompleted: true,
+	GenerationFailed:    true,
+	DeckUpdated:         true,
+	PurchaseVerified:    true,
+}
+
+const (
+	// maxDeliveries bounds the delivery log kept on disk
+	maxDeliveries = 500
+
+	initialBackoff = time.Second
+	requestTimeout = 10 * time.Second
+)
+
+// Subscription is an outbound webhook endpoint
+type Subscription struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"` // HMAC-SHA256 key for X-Webhook-Signature, generated when empty
+	Events    []string  `json:"events"`           // Empty means all events
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (s *Subscription) wants(event string) bool {
+	if !s.Active {
+		return false
+	}
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, e := range s.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery records one event sent to one subscription
+type Delivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscriptionId"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"` // pending, delivered, failed
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"responseStatus,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+}
+
+// envelope is the JSON body posted to subscribers
+type envelope struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+// Dispatcher delivers signed event payloads to webhook subscriptions
+// Subscriptions and the delivery log are stored as JSON files, secrets included,
+// so the directory must not be served
+type Dispatcher struct {
+	path        string
+	maxAttempts int
+	client      *http.Client
+
+	subscriptions []*Subscription
+	deliveries    []*Delivery
+	published     map[string]time.Time // Keys passed to PublishOnce, hashed
+	mu            sync.Mutex
+}
+
+func NewDispatcher(path string, maxAttempts int) *Dispatcher {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	d := &Dispatcher{
+		path:        path,
+		maxAttempts: maxAttempts,
+		client:      &http.Client{Timeout: requestTimeout},
+		published:   make(map[string]time.Time),
+	}
+	d.load()
+	return d
+}
+
+func (d *Dispatcher) load() {
+	if data, err := os.ReadFile(filepath.Join(d.path, "subscriptions.json")); err == nil {
+		json.Unmarshal(data, &d.subscriptions)
+	}
+	if data, err := os.ReadFile(filepath.Join(d.path, "deliveries.json")); err == nil {
+		json.Unmarshal(data, &d.deliveries)
+	}
+	if data, err := os.ReadFile(filepath.Join(d.path, "published.json")); err == nil {
+		json.Unmarshal(data, &d.published)
+	}
+	if d.published == nil {
+		d.published = make(map[string]time.Time)
+	}
+
+	// Every payload is signed, subscriptions stored without a secret get a fresh one
+	// that receivers only learn by recreating the subscription
+	generated := false
+	for _, sub := range d.subscriptions {
+		if sub.Secret == "" {
+			sub.Secret = newSecret()
+			generated = true
+			log.Printf("Webhooks: subscription %s had no secret, recreate it to verify signatures", sub.ID)
+		}
+	}
+	if generated {
+		// Stored right away so the secret does not change on the next restart
+		d.persist()
+	}
+
+	// Retries do not survive a restart, let them be redelivered manually
+	for _, delivery := range d.deliveries {
+		if delivery.Status == "pending" {
+			delivery.Status = "failed"
+			delivery.LastError = "interrupted by server restart"
+		}
+	}
+}
+
+// persist writes subscriptions and deliveries to disk, d.mu must be held
+func (d *Dispatcher) persist() {
+	if err := os.MkdirAll(d.path, 0755); err != nil {
+		log.Printf("Webhooks: failed to create directory: %v", err)
+		return
+	}
+
+	files := map[string]interface{}{
+		"subscriptions.json": d.subscriptions,
+		"deliveries.json":    d.deliveries,
+		"published.json":     d.published,
+	}
+	for name, value := range files {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(d.path, name), data, 0644); err != nil {
+			log.Printf("Webhooks: failed to write %s: %v", name, err)
+		}
+	}
+}
+
+// Subscriptions returns all configured subscriptions
+// Secrets are only returned when a subscription is created
+func (d *Dispatcher) Subscriptions() []Subscription {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	subs := make([]Subscription, 0, len(d.subscriptions))
+	for _, s := range d.subscriptions {
+		sub := *s
+		sub.Secret = ""
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+// AddSubscription validates and stores a new subscription
+// A secret is generated when none is given, the returned copy is the only place it is exposed
+func (d *Dispatcher) AddSubscription(sub Subscription) (*Subscription, error) {
+	if sub.URL == "" {
+		return nil, fmt.Errorf("url required")
+	}
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid url, expected an absolute http or https url: %s", sub.URL)
+	}
+	for _, e := range sub.Events {
+		if !knownEvents[e] {
+			return nil, fmt.Errorf("unknown event: %s", e)
+		}
+	}
+
+	sub.ID = newID()
+	sub.CreatedAt = time.Now()
+	if sub.Secret == "" {
+		sub.Secret = newSecret()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stored := sub
+	d.subscriptions = append(d.subscriptions, &stored)
+	d.persist()
+	return &sub, nil
+}
+
+func (d *Dispatcher) DeleteSubscription(id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i, s := range d.subscriptions {
+		if s.ID == id {
+			d.subscriptions = append(d.subscriptions[:i], d.subscriptions[i+1:]...)
+			d.persist()
+			return nil
+		}
+	}
+	return fmt.Errorf("subscription not found: %s", id)
+}
+
+// Deliveries returns the delivery log, newest first
+func (d *Dispatcher) Deliveries() []Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	out := make([]Delivery, 0, len(d.deliveries))
+	for i := len(d.deliveries) - 1; i >= 0; i-- {
+		out = append(out, *d.deliveries[i])
+	}
+	return out
+}
+
+// Publish queues an event for every subscription interested in it
+// Safe to call on a nil Dispatcher
+func (d *Dispatcher) Publish(event string, data interface{}) {
+	if d == nil {
+		return
+	}
+
+	now := time.Now()
+
+	d.mu.Lock()
+	var queued []*Delivery
+	for _, sub := range d.subscriptions {
+		if !sub.wants(event) {
+			continue
+		}
+
+		delivery := &Delivery{
+			ID:             newID(),
+			SubscriptionID: sub.ID,
+			Event:          event,
+			Status:         "pending",
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		payload, err := json.Marshal(envelope{ID: delivery.ID, Event: event, CreatedAt: now, Data: data})
+		if err != nil {
+			log.Printf("Webhooks: failed to marshal %s: %v", event, err)
+			continue
+		}
+		delivery.Payload = payload
+
+		d.deliveries = append(d.deliveries, delivery)
+		queued = append(queued, delivery)
+	}
+	if len(d.deliveries) > maxDeliveries {
+		d.deliveries = d.deliveries[len(d.deliveries)-maxDeliveries:]
+	}
+	if len(queued) > 0 {
+		d.persist()
+	}
+	d.mu.Unlock()
+
+	for _, delivery := range queued {
+		go d.deliver(delivery)
+	}
+}
+
+// PublishOnce publishes an event the first time key is seen and reports whether it did
+// Keys are kept across restarts, e.g. so a purchase verified again is not reported twice
+// Safe to call on a nil Dispatcher
+func (d *Dispatcher) PublishOnce(event, key string, data interface{}) bool {
+	if d == nil {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(event + "\x00" + key))
+	hash := hex.EncodeToString(sum[:])
+
+	d.mu.Lock()
+	if _, seen := d.published[hash]; seen {
+		d.mu.Unlock()
+		return false
+	}
+	d.published[hash] = time.Now()
+	d.persist()
+	d.mu.Unlock()
+
+	d.Publish(event, data)
+	return true
+}
+
+// Redeliver sends a logged delivery again with a fresh retry budget
+func (d *Dispatcher) Redeliver(id string) (*Delivery, error) {
+	d.mu.Lock()
+	var delivery *Delivery
+	for _, dl := range d.deliveries {
+		if dl.ID == id {
+			delivery = dl
+			break
+		}
+	}
+	if delivery == nil {
+		d.mu.Unlock()
+		return nil, fmt.Errorf("delivery not found: %s", id)
+	}
+	if delivery.Status == "pending" {
+		d.mu.Unlock()
+		return nil, fmt.Errorf("delivery %s is still pending", id)
+	}
+
+	delivery.Status = "pending"
+	delivery.Attempts = 0
+	delivery.UpdatedAt = time.Now()
+	snapshot := *delivery
+	d.persist()
+	d.mu.Unlock()
+
+	go d.deliver(delivery)
+	return &snapshot, nil
+}
+
+// deliver posts a delivery, retrying with exponential backoff
+func (d *Dispatcher) deliver(delivery *Delivery) {
+	backoff := initialBackoff
+
+	for {
+		d.mu.Lock()
+		sub := d.subscription(delivery.SubscriptionID)
+		payload := delivery.Payload
+		d.mu.Unlock()
+
+		if sub == nil {
+			d.finish(delivery, "failed", 0, fmt.Errorf("subscription deleted"))
+			return
+		}
+
+		status, err := d.send(sub, delivery.ID, delivery.Event, payload)
+
+		d.mu.Lock()
+		delivery.Attempts++
+		attempts := delivery.Attempts
+		d.mu.Unlock()
+
+		if err == nil {
+			d.finish(delivery, "delivered", status, nil)
+			return
+		}
+		if attempts >= d.maxAttempts {
+			d.finish(delivery, "failed", status, err)
+			return
+		}
+
+		d.mu.Lock()
+		delivery.ResponseStatus = status
+		delivery.LastError = err.Error()
+		delivery.UpdatedAt = time.Now()
+		d.mu.Unlock()
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (d *Dispatcher) finish(delivery *Delivery, status string, responseStatus int, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delivery.Status = status
+	delivery.ResponseStatus = responseStatus
+	delivery.LastError = ""
+	if err != nil {
+		delivery.LastError = err.Error()
+	}
+	delivery.UpdatedAt = time.Now()
+	d.persist()
+}
+
+// subscription returns a copy of the subscription with id, d.mu must be held
+func (d *Dispatcher) subscription(id string) *Subscription {
+	for _, s := range d.subscriptions {
+		if s.ID == id {
+			sub := *s
+			return &sub
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) send(sub *Subscription, deliveryID, event string, payload []byte) (int, error) {
+	req, err := http.NewRequest("POST", sub.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", event)
+	req.Header.Set("X-Webhook-Delivery", deliveryID)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+Sign(sub.Secret, timestamp, payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "timestamp.payload" keyed by secret
+// Receivers recompute it to verify X-Webhook-Signature
+func Sign(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func newSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -55,6 +55,23 @@ func (s *LocalStorage) SaveReader(deckID, cardID, filename string, reader io.Rea
 	return url, nil
 }
 
+func (s *LocalStorage) Load(deckID, cardID, filename string) ([]byte, error) {
+	path := filepath.Join(s.basePath, deckID, cardID, filename)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return data, nil
+}
+
+func (s *LocalStorage) DeleteFile(deckID, cardID, filename string) error {
+	path := filepath.Join(s.basePath, deckID, cardID, filename)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
 func (s *LocalStorage) Delete(deckID, cardID string) error {
 	dir := filepath.Join(s.basePath, deckID, cardID)
 	return os.RemoveAll(dir)